@@ -0,0 +1,92 @@
+/*
+Package auth defines the "auth" command for the vaultx CLI.
+
+The auth command migrates auth method mounts between Vault instances, using the same
+VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN environment variables as "secrets copy".
+
+Usage hierarchy:
+  vaultx auth [subcommand]
+
+Available subcommands:
+  copy     - Enable the source's auth methods on the target and copy their roles.
+
+Usage:
+  vaultx auth copy
+
+Credentials issued by auth methods (AppRole secret IDs, userpass passwords, tokens) and
+method configuration containing secrets cannot be copied; a warning is logged for each
+affected method.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/razahuss02/vaultx/pkg/auth"
+	"github.com/urfave/cli/v3"
+)
+
+func AuthCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "Manage auth method mounts",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
+		Commands: []*cli.Command{
+			CopyCommand(),
+		},
+	}
+}
+
+func CopyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "copy",
+		Usage: "Copy auth method mounts and roles from one vault instance to another",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return CopyAuthMethods(ctx, cmd)
+		},
+	}
+}
+
+// CopyAuthMethods enables the source Vault's auth methods on the target Vault and copies
+// their roles.
+func CopyAuthMethods(ctx context.Context, cmd *cli.Command) error {
+	sourceClient := vaultclient.GetVaultClient(ctx)
+	if sourceClient == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	targetClient, err := vaultclient.NewTargetClient()
+	if err != nil {
+		slog.Error("Failed to initialize target vault client", "error", err)
+		os.Exit(1)
+	}
+	if err := vaultclient.CheckSealed(ctx, targetClient, "target"); err != nil {
+		slog.Error("Vault is sealed", "error", err)
+		return err
+	}
+
+	result, err := auth.Copy(ctx, sourceClient, targetClient)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(color.Summary("auth copy finished",
+		color.Count{Label: "enabled", N: len(result.Enabled), Paint: color.Green},
+		color.Count{Label: "existing", N: len(result.Existing), Paint: color.Yellow},
+		color.Count{Label: "roles", N: len(result.Roles), Paint: color.Green},
+		color.Count{Label: "failed", N: len(result.Failed), Paint: color.Red},
+	))
+	if len(result.Failed) > 0 {
+		return errors.New("some auth methods or roles could not be copied")
+	}
+	return nil
+}
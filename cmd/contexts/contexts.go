@@ -0,0 +1,98 @@
+/*
+Package contexts defines the "context" command for the vaultx CLI.
+
+The context command switches between the named Vault environments defined in the vaultx
+config file, in the style of kubectl contexts. The selected environment supplies the
+Vault address and token used by every other command where VAULT_ADDR and VAULT_TOKEN are
+unset. The global --context flag selects another environment for one invocation, and its
+address and token override those variables.
+
+Usage hierarchy:
+  vaultx context [subcommand]
+
+Available subcommands:
+  list     - List the environments defined in the config file.
+  use      - Persist the given environment as the current one.
+  current  - Print the current environment.
+
+These commands only read and write the config file and never contact Vault.
+*/
+
+package contexts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+func ContextCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "context",
+		Usage: "Switch between named Vault environments",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List environments defined in the config file",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cfg := config.FromContext(ctx)
+
+					names := make([]string, 0, len(cfg.Environments))
+					for name := range cfg.Environments {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+
+					for _, name := range names {
+						marker := " "
+						if name == cfg.CurrentName() {
+							marker = "*"
+						}
+						fmt.Printf("%s %s\t%s\n", marker, name, cfg.Environments[name].Addr)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "Set the current environment",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					name := cmd.Args().First()
+					if name == "" {
+						return errors.New("environment name is required")
+					}
+
+					cfg := config.FromContext(ctx)
+					if _, ok := cfg.Environments[name]; !ok {
+						return fmt.Errorf("environment %q is not defined in config", name)
+					}
+
+					if err := config.SetEnvironment(cfg.Path, name); err != nil {
+						return err
+					}
+
+					fmt.Printf("Switched to context %q.\n", name)
+					return nil
+				},
+			},
+			{
+				Name:  "current",
+				Usage: "Print the current environment",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cfg := config.FromContext(ctx)
+					if cfg.CurrentName() == "" {
+						return errors.New("no current context is set")
+					}
+
+					fmt.Println(cfg.CurrentName())
+					return nil
+				},
+			},
+		},
+	}
+}
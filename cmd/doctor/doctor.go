@@ -0,0 +1,237 @@
+/*
+Package doctor defines the "doctor" command for the vaultx CLI.
+
+The doctor command checks that vaultx is set up to talk to Vault and prints a pass/fail
+checklist, so new users can find configuration problems themselves instead of decoding the
+first failed request of a real operation.
+
+Usage:
+  vaultx doctor
+
+For the source Vault (VAULT_ADDR), and for the target Vault (VAULT_TARGET_ADDR) when any
+VAULT_TARGET_* connection variable is set, it checks:
+  - that an address and token are configured, and where the token comes from
+  - that Vault is reachable, initialized and unsealed
+  - that the token is valid, and how long it has left
+  - that the token has policies beyond "default"
+  - which KV mounts the token can see, with their detected versions
+
+Checks that depend on an earlier one that failed are skipped. The command exits with an
+error if any check failed; warnings don't affect the exit status. The token itself is never
+printed.
+*/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// status is the outcome of one check.
+type status string
+
+const (
+	statusPass status = "PASS"
+	statusWarn status = "WARN"
+	statusFail status = "FAIL"
+	statusSkip status = "SKIP"
+)
+
+// result is one line of the checklist.
+type result struct {
+	Status status
+	Check  string
+	Detail string
+}
+
+func DoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the Vault connection settings, token and visible KV mounts",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return RunDoctor(ctx)
+		},
+	}
+}
+
+// RunDoctor runs every check, prints the checklist and a summary, and returns an error
+// if any check failed.
+func RunDoctor(ctx context.Context) error {
+	results := checkSource(ctx)
+	results = append(results, checkTarget(ctx)...)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	counts := map[status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Fprintf(w, "%s\t%s\t%s\n", paintStatus(r.Status), r.Check, r.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println(color.Summary("doctor finished",
+		color.Count{Label: "passed", N: counts[statusPass], Paint: color.Green},
+		color.Count{Label: "warnings", N: counts[statusWarn], Paint: color.Yellow},
+		color.Count{Label: "failed", N: counts[statusFail], Paint: color.Red},
+		color.Count{Label: "skipped", N: counts[statusSkip]},
+	))
+	if counts[statusFail] > 0 {
+		return fmt.Errorf("%d check(s) failed", counts[statusFail])
+	}
+	return nil
+}
+
+// checkSource checks the Vault instance that commands operate on.
+func checkSource(ctx context.Context) []result {
+	cfg := config.FromContext(ctx)
+	client, err := vaultclient.NewSourceClient(cfg)
+	if err != nil {
+		return append([]result{{statusFail, "source configured", err.Error()}}, skipRest("source")...)
+	}
+
+	tokenSource := "the config environment or ~/.vault-token"
+	switch {
+	case cfg.Context != "":
+		tokenSource = fmt.Sprintf("the %q config environment (--context)", cfg.Context)
+	case os.Getenv("VAULT_TOKEN") != "":
+		tokenSource = "VAULT_TOKEN"
+	case os.Getenv("VAULT_TOKEN_SINK") != "":
+		tokenSource = "the VAULT_TOKEN_SINK file"
+	}
+	configured := result{statusPass, "source configured", fmt.Sprintf("%s, token from %s", client.Configuration().Address, tokenSource)}
+	return append([]result{configured}, checkInstance(ctx, client, "source")...)
+}
+
+// checkTarget checks the target Vault of copy operations, if any VAULT_TARGET_*
+// connection variable is set.
+func checkTarget(ctx context.Context) []result {
+	if os.Getenv("VAULT_TARGET_ADDR") == "" && os.Getenv("VAULT_TARGET_TOKEN") == "" && os.Getenv("VAULT_TARGET_TOKEN_SINK") == "" {
+		return []result{{statusSkip, "target configured", "VAULT_TARGET_ADDR is unset; a target is only needed for secrets copy"}}
+	}
+
+	client, err := vaultclient.NewTargetClient()
+	if err != nil {
+		return append([]result{{statusFail, "target configured", err.Error()}}, skipRest("target")...)
+	}
+	configured := result{statusPass, "target configured", client.Configuration().Address}
+	return append([]result{configured}, checkInstance(ctx, client, "target")...)
+}
+
+// checkInstance runs the connectivity, token and mount checks against one Vault
+// instance, named "source" or "target".
+func checkInstance(ctx context.Context, client *vault.Client, name string) []result {
+	reachable := checkHealth(ctx, client, name)
+	if reachable.Status == statusFail {
+		return append([]result{reachable}, skipAfterHealth(name)...)
+	}
+
+	token, policies, valid := checkToken(ctx, client, name)
+	if !valid {
+		return []result{reachable, token, {statusSkip, name + " policies", "token is not valid"}, {statusSkip, name + " KV mounts", "token is not valid"}}
+	}
+	return []result{reachable, token, policies, checkMounts(ctx, client, name)}
+}
+
+// checkHealth queries sys/health, which answers without a token.
+func checkHealth(ctx context.Context, client *vault.Client, name string) result {
+	check := name + " Vault reachable"
+	addr := client.Configuration().Address
+
+	health, err := vaultclient.CheckHealth(ctx, client, name)
+	switch {
+	case err != nil:
+		return result{statusFail, check, err.Error()}
+	case health.Standby:
+		return result{statusPass, check, fmt.Sprintf("%s, unsealed standby node, Vault %s", addr, health.Version)}
+	}
+	return result{statusPass, check, fmt.Sprintf("%s, unsealed, Vault %s", addr, health.Version)}
+}
+
+// checkToken looks up the client's token, returning the token and policies checks and
+// whether the token is valid.
+func checkToken(ctx context.Context, client *vault.Client, name string) (result, result, bool) {
+	check := name + " token valid"
+	info, err := vaultclient.LookupToken(ctx, client, name)
+	if err != nil {
+		return result{statusFail, check, err.Error()}, result{}, false
+	}
+
+	token := result{statusPass, check, "never expires"}
+	if info.TTL > 0 {
+		token.Detail = "expires in " + info.TTL.String()
+		if info.TTL < vaultclient.MinTokenTTL {
+			token.Status = statusWarn
+			token.Detail += "; long operations may not finish before it does"
+		}
+	}
+
+	policyCheck := result{statusPass, name + " policies", strings.Join(info.Policies, ", ")}
+	if !slices.ContainsFunc(info.Policies, func(p string) bool { return p != "default" }) {
+		policyCheck = result{statusWarn, name + " policies", "token has only the default policy, which can't read or write secrets"}
+	}
+	return token, policyCheck, true
+}
+
+// checkMounts lists the KV mounts visible to the client's token.
+func checkMounts(ctx context.Context, client *vault.Client, name string) result {
+	check := name + " KV mounts"
+	mounts, err := kv.GetSecretEngines(ctx, client)
+	if err != nil {
+		if vault.IsErrorStatus(err, http.StatusForbidden) {
+			return result{statusFail, check, "token can't list mounts (needs read on sys/mounts)"}
+		}
+		return result{statusFail, check, fmt.Sprintf("failed to list mounts: %v", err)}
+	}
+	if len(mounts) == 0 {
+		return result{statusWarn, check, "no KV mounts found"}
+	}
+
+	names := make([]string, 0, len(mounts))
+	for mountPath, info := range mounts {
+		names = append(names, fmt.Sprintf("%s (v%s)", kv.NormalizeMount(mountPath), info.Version))
+	}
+	sort.Strings(names)
+	return result{statusPass, check, strings.Join(names, ", ")}
+}
+
+// skipRest returns the checks skipped when no client could be created for the instance.
+func skipRest(name string) []result {
+	return append([]result{{statusSkip, name + " Vault reachable", "no address and token configured"}}, skipAfterHealth(name)...)
+}
+
+// skipAfterHealth returns the checks skipped when the instance can't be used.
+func skipAfterHealth(name string) []result {
+	reason := "Vault is not usable"
+	return []result{
+		{statusSkip, name + " token valid", reason},
+		{statusSkip, name + " policies", reason},
+		{statusSkip, name + " KV mounts", reason},
+	}
+}
+
+func paintStatus(s status) string {
+	switch s {
+	case statusPass:
+		return color.Green(string(s))
+	case statusWarn:
+		return color.Yellow(string(s))
+	case statusFail:
+		return color.Red(string(s))
+	}
+	return string(s)
+}
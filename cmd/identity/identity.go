@@ -0,0 +1,92 @@
+/*
+Package identity defines the "identity" command for the vaultx CLI.
+
+The identity command backs up Vault's identity store, which is commonly forgotten during
+migrations because it lives outside any secrets engine.
+
+Usage hierarchy:
+  vaultx identity [subcommand]
+
+Available subcommands:
+  export   - Write every entity and group, with aliases and memberships, as JSON.
+
+Usage:
+  vaultx identity export [--out=<file>]
+
+Flags:
+  --out   Write the export to this file instead of stdout.
+*/
+
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/razahuss02/vaultx/pkg/identity"
+	"github.com/urfave/cli/v3"
+)
+
+func IdentityCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "identity",
+		Usage: "Back up identity entities and groups",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
+		Commands: []*cli.Command{
+			ExportCommand(),
+		},
+	}
+}
+
+func ExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export identity entities and groups as JSON",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "Write the export to this file instead of stdout",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return ExportIdentity(ctx, cmd)
+		},
+	}
+}
+
+// ExportIdentity writes every identity entity and group, with their aliases and group
+// memberships, as indented JSON to stdout or --out.
+func ExportIdentity(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	export, err := identity.Export(ctx, client)
+	if err != nil {
+		slog.Error("failed to export identity store", "error", err)
+		return err
+	}
+
+	out, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+
+	if file := cmd.String("out"); file != "" {
+		if err := os.WriteFile(file, out, 0o600); err != nil {
+			return err
+		}
+		slog.Info("identity store exported", "file", file, "entities", len(export.Entities), "groups", len(export.Groups))
+		return nil
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
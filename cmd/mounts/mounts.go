@@ -0,0 +1,182 @@
+/*
+Package mounts defines the "mounts" command for the vaultx CLI.
+
+The mounts command shows the KV mounts vaultx sees and the version it detected for each, so
+operators can check the layout, and spot misdetected mounts, before copying.
+
+Usage hierarchy:
+  vaultx mounts [subcommand]
+
+Available subcommands:
+  list     - Print every KV mount with its engine type and detected KV version.
+  enable   - Enable a KV engine of a given version, e.g. on a target before "secrets copy".
+
+Usage:
+  vaultx mounts list [--json]
+  vaultx mounts enable --path=<mount> [--version=1|2] [--target]
+
+Flags:
+  --json      Print the mounts as JSON instead of a table.
+  --path      The path to enable the KV engine at.
+  --version   The KV version to enable (default 2).
+  --target    Enable the mount on the target Vault (VAULT_TARGET_ADDR) instead of the source.
+
+Enabling a mount that already exists as a KV engine of the requested version succeeds
+without changing it, so "mounts enable" can be re-run safely in migration scripts.
+*/
+
+package mounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func MountsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "mounts",
+		Usage: "Inspect KV secret engine mounts",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
+		Commands: []*cli.Command{
+			ListCommand(),
+			EnableCommand(),
+		},
+	}
+}
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List KV mounts with their engine type and detected KV version",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the mounts as JSON",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return ListMounts(ctx, cmd)
+		},
+	}
+}
+
+// mountEntry is one row of the mount listing.
+type mountEntry struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+// ListMounts prints the KV mounts reported by GetSecretEngines, sorted by path, as a
+// table or, with --json, as a JSON array.
+func ListMounts(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	mounts, err := kv.GetSecretEngines(ctx, client)
+	if err != nil {
+		slog.Error("unable to list KV secret engines", "error", err)
+		return err
+	}
+
+	entries := make([]mountEntry, 0, len(mounts))
+	for mountPath, mountInfo := range mounts {
+		entries = append(entries, mountEntry{Path: mountPath, Type: mountInfo.Type, Version: mountInfo.Version})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	if cmd.Bool("json") {
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tTYPE\tKV VERSION")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Path, entry.Type, entry.Version)
+	}
+	return w.Flush()
+}
+
+func EnableCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "enable",
+		Usage: "Enable a KV secrets engine at a path",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "path",
+				Usage: "Path to enable the KV engine at",
+			},
+			&cli.StringFlag{
+				Name:  "version",
+				Value: "2",
+				Usage: "KV version to enable (1 or 2)",
+			},
+			&cli.BoolFlag{
+				Name:  "target",
+				Usage: "Enable the mount on the target Vault (VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if strings.Trim(cmd.String("path"), "/") == "" {
+				slog.Error("--path flag is required")
+				os.Exit(1)
+			}
+			if version := cmd.String("version"); version != "1" && version != "2" {
+				slog.Error("--version must be 1 or 2", "value", version)
+				os.Exit(1)
+			}
+			return EnableMount(ctx, cmd)
+		},
+	}
+}
+
+// EnableMount enables a KV engine of --version at --path on the source Vault, or the
+// target with --target, succeeding without changes if an identical mount already exists.
+func EnableMount(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+	if cmd.Bool("target") {
+		targetClient, err := vaultclient.NewTargetClient()
+		if err != nil {
+			slog.Error("Failed to initialize target vault client", "error", err)
+			os.Exit(1)
+		}
+		client = targetClient
+	}
+
+	mount := strings.Trim(cmd.String("path"), "/")
+	version := cmd.String("version")
+
+	created, err := kv.EnableKVMount(ctx, client, mount, version)
+	if err != nil {
+		slog.Error("failed to enable KV mount", "mount", mount, "version", version, "error", err)
+		return err
+	}
+	if created {
+		slog.Info("KV mount enabled", "mount", mount, "version", version)
+	} else {
+		slog.Info("KV mount already exists", "mount", mount, "version", version)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Package policy defines the "policy" command for the vaultx CLI.
+
+The policy command migrates ACL policies between Vault instances, using the same
+VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN environment variables as "secrets copy".
+
+Usage hierarchy:
+  vaultx policy [subcommand]
+
+Available subcommands:
+  copy     - Copy ACL policies from the source to the target instance.
+
+Usage:
+  vaultx policy copy [--include=<glob>] [--exclude=<glob>] [--dry-run]
+
+Flags:
+  --include   Only copy policies whose name matches this glob. Repeatable.
+  --exclude   Skip policies whose name matches this glob. Repeatable; wins over --include.
+  --dry-run   Report which policies would be copied without writing them.
+
+The built-in "root" policy is never copied.
+*/
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/razahuss02/vaultx/pkg/policy"
+	"github.com/urfave/cli/v3"
+)
+
+func PolicyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "policy",
+		Usage: "Manage ACL policies",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
+		Commands: []*cli.Command{
+			CopyCommand(),
+		},
+	}
+}
+
+func CopyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "copy",
+		Usage: "Copy ACL policies from one vault instance to another",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "Only copy policies whose name matches this glob (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Skip policies whose name matches this glob (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Report which policies would be copied without writing them",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return CopyPolicies(ctx, cmd)
+		},
+	}
+}
+
+// CopyPolicies copies the ACL policies selected by --include and --exclude from the
+// source Vault to the target Vault.
+func CopyPolicies(ctx context.Context, cmd *cli.Command) error {
+	sourceClient := vaultclient.GetVaultClient(ctx)
+	if sourceClient == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	targetClient, err := vaultclient.NewTargetClient()
+	if err != nil {
+		slog.Error("Failed to initialize target vault client", "error", err)
+		os.Exit(1)
+	}
+	if err := vaultclient.CheckSealed(ctx, targetClient, "target"); err != nil {
+		slog.Error("Vault is sealed", "error", err)
+		return err
+	}
+
+	result, err := policy.Copy(ctx, sourceClient, targetClient, policy.CopyOptions{
+		Include: cmd.StringSlice("include"),
+		Exclude: cmd.StringSlice("exclude"),
+		DryRun:  cmd.Bool("dry-run"),
+	})
+	if err != nil {
+		return err
+	}
+
+	operation := "policy copy finished"
+	if cmd.Bool("dry-run") {
+		operation = "policy copy dry run finished"
+	}
+	fmt.Println(color.Summary(operation,
+		color.Count{Label: "copied", N: len(result.Copied), Paint: color.Green},
+		color.Count{Label: "skipped", N: len(result.Skipped), Paint: color.Yellow},
+		color.Count{Label: "failed", N: len(result.Failed), Paint: color.Red},
+	))
+	if len(result.Failed) > 0 {
+		return errors.New("some policies could not be copied")
+	}
+	return nil
+}
@@ -1,15 +1,32 @@
 /*
 Package cmd defines the root command for the vaultx CLI.
 
-The root command initializes the CLI application, sets up global context such as Vault authentication,
-and registers all top-level subcommands. Currently, it includes the "secrets" subcommand for managing Vault secrets.
+The root command initializes the CLI application, sets up global context such as Vault
+authentication, and registers the top-level subcommands:
+  - "secrets" for managing Vault KV secrets
+  - "identity" for backing up identity entities and groups
+  - "mounts" for inspecting KV mounts
+  - "policy" and "auth" for migrating ACL policies and auth methods
+  - "token" and "whoami" for inspecting the current token
+  - "context" for switching between named Vault environments
+  - "doctor" for diagnosing setup problems
 
 Usage:
   vaultx [command] [subcommand] [flags]
 
 Features:
   - Initializes a Vault client context shared across subcommands
+  - Loads defaults and named Vault environments from ~/.vaultx.yaml (or --config)
+  - Selects a named environment per invocation via --context
+  - Loads VAULT_* connection variables from a dotenv-style file via --env-file
+  - Hides per-secret progress logging with --quiet
+  - Redacts secret values from log output unless --unsafe-log-values is set
+  - Tags every Vault request and log line with an operation ID (--request-id, or a random UUID)
+  - Tunes HTTP connection pooling and timeouts via --max-idle-conns and --connect-timeout
+  - Colors operation summaries on terminals unless --no-color or NO_COLOR is set
+  - Stops cleanly on the first Ctrl-C, printing the summary of what completed so far
   - Registers CLI commands using urfave/cli
+  - Prints shell completion scripts via "vaultx completion bash|zsh|fish"
   - Supports versioning via the Version variable
 
 This package serves as the entry point for the CLI and should be called from the main function.
@@ -18,9 +35,25 @@ This package serves as the entry point for the CLI and should be called from the
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/razahuss02/vaultx/cmd/auth"
+	"github.com/razahuss02/vaultx/cmd/contexts"
+	"github.com/razahuss02/vaultx/cmd/doctor"
+	"github.com/razahuss02/vaultx/cmd/identity"
+	"github.com/razahuss02/vaultx/cmd/mounts"
+	"github.com/razahuss02/vaultx/cmd/policy"
 	"github.com/razahuss02/vaultx/cmd/secrets"
+	"github.com/razahuss02/vaultx/cmd/token"
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/razahuss02/vaultx/internal/logging"
 	"github.com/razahuss02/vaultx/internal/vaultclient"
 	"github.com/urfave/cli/v3"
 )
@@ -28,20 +61,142 @@ import (
 var Version = "dev"
 
 func RootCommand() error {
-
-	ctx, err := vaultclient.InitVaultContext()
-	if err != nil {
-		return err
-	}
-
 	cmd := &cli.Command{
 		Name:    "vaultx",
 		Usage:   "Vault extension CLI",
 		Version: Version,
+		// adds the hidden "completion" command that prints bash, zsh, fish and pwsh scripts
+		EnableShellCompletion: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Path to the vaultx config file (default ~/.vaultx.yaml)",
+			},
+			&cli.StringFlag{
+				Name:  "context",
+				Usage: "Named Vault environment from the config file to use for this invocation",
+			},
+			&cli.StringFlag{
+				Name:  "env-file",
+				Usage: "Dotenv-style file of VAULT_ADDR, VAULT_TOKEN and VAULT_TARGET_* settings; variables already in the environment take precedence",
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Log level: debug, info, warn or error",
+			},
+			&cli.BoolFlag{
+				Name:    "quiet",
+				Aliases: []string{"q"},
+				Usage:   "Only log warnings and errors; operation summaries are still printed",
+			},
+			&cli.BoolFlag{
+				Name:  "unsafe-log-values",
+				Usage: "Disable redaction of secret values in log output",
+			},
+			&cli.StringFlag{
+				Name:  "request-id",
+				Usage: "Operation ID sent to Vault with every request and included in log output (default: a random UUID)",
+			},
+			&cli.IntFlag{
+				Name:    "max-idle-conns",
+				Usage:   "Idle HTTP connections kept open to each Vault server; 0 keeps the client default; raise to at least --threads for concurrent copies",
+				Sources: cli.EnvVars("VAULTX_MAX_IDLE_CONNS"),
+			},
+			&cli.DurationFlag{
+				Name:    "connect-timeout",
+				Value:   30 * time.Second,
+				Usage:   "Timeout for establishing a connection to Vault",
+				Sources: cli.EnvVars("VAULTX_CONNECT_TIMEOUT"),
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable colored output (also disabled when stdout is not a terminal or NO_COLOR is set)",
+			},
+		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if path := cmd.String("env-file"); path != "" {
+				if err := config.LoadEnvFile(path); err != nil {
+					return nil, err
+				}
+			}
+
+			logging.Setup(cmd.Bool("unsafe-log-values"))
+			color.Setup(cmd.Bool("no-color"))
+
+			requestID := cmd.String("request-id")
+			if requestID == "" {
+				var err error
+				if requestID, err = vaultclient.NewRequestID(); err != nil {
+					return nil, fmt.Errorf("failed to generate request ID: %w", err)
+				}
+			}
+			vaultclient.SetRequestID(requestID)
+			vaultclient.SetTransportOptions(vaultclient.TransportOptions{
+				MaxIdleConns:   cmd.Int("max-idle-conns"),
+				ConnectTimeout: cmd.Duration("connect-timeout"),
+			})
+			slog.SetDefault(slog.Default().With("request_id", requestID))
+
+			cfg, err := config.Load(cmd.String("config"))
+			if err != nil {
+				return nil, err
+			}
+			if name := cmd.String("context"); name != "" {
+				cfg.Context = name
+			}
+			ctx = config.WithConfig(ctx, cfg)
+
+			if err := setLogLevel(cmd, cfg); err != nil {
+				return nil, err
+			}
+
+			return ctx, nil
+		},
 		Commands: []*cli.Command{
+			auth.AuthCommand(),
+			contexts.ContextCommand(),
+			doctor.DoctorCommand(),
+			identity.IdentityCommand(),
+			mounts.MountsCommand(),
+			policy.PolicyCommand(),
 			secrets.SecretsCommand(),
+			token.TokenCommand(),
+			token.WhoamiCommand(),
 		},
 	}
 
+	// the first Ctrl-C cancels ctx so long runs can stop cleanly and report what they
+	// did; once it has, a second one kills the process as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	return cmd.Run(ctx, os.Args)
 }
+
+// setLogLevel applies --log-level, falling back to the config file's log-level. --quiet
+// overrides both with the warn level, hiding per-secret progress.
+func setLogLevel(cmd *cli.Command, cfg *config.Config) error {
+	if cmd.Bool("quiet") {
+		logging.SetLevel(slog.LevelWarn)
+		return nil
+	}
+
+	level := cmd.String("log-level")
+	if level == "" {
+		level = cfg.LogLevel
+	}
+	if level == "" {
+		return nil
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	logging.SetLevel(l)
+	return nil
+}
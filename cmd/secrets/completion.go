@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// completionTimeout bounds the Vault requests made to suggest mount names, so that an
+// unreachable Vault stalls the shell's prompt only briefly.
+const completionTimeout = 2 * time.Second
+
+// completeMounts is a ShellComplete func that suggests KV mount names when the word being
+// completed is the value of a mount flag, and flag names otherwise. --target-mount is
+// completed against the target Vault.
+func completeMounts(ctx context.Context, cmd *cli.Command) {
+	// the completion script always appends --generate-shell-completion, so the word
+	// before it is the partial flag, or the flag whose value is being completed
+	if len(os.Args) < 2 {
+		return
+	}
+	last := os.Args[len(os.Args)-2]
+
+	switch strings.TrimLeft(last, "-") {
+	case "source-mount", "mount":
+		printMountNames(ctx, cmd, false)
+		return
+	case "target-mount":
+		printMountNames(ctx, cmd, true)
+		return
+	}
+
+	// urfave/cli's default completer relies on parsed arguments, which are empty when
+	// the partial flag fails to parse, so match flag names here instead
+	if strings.HasPrefix(last, "-") {
+		for _, flag := range cmd.Flags {
+			if name := flag.Names()[0]; strings.HasPrefix(name, strings.TrimLeft(last, "-")) {
+				fmt.Fprintln(cmd.Root().Writer, "--"+name)
+			}
+		}
+	}
+}
+
+// printMountNames writes the KV mounts on the source (or target) Vault one per line.
+// Completion must stay quiet, so any failure simply produces no suggestions.
+func printMountNames(ctx context.Context, cmd *cli.Command, target bool) {
+	// the client and mount lookups log their failures to stderr, which the shell would
+	// print over the prompt
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	// Before hooks don't run during completion, so the config isn't in ctx yet
+	cfg, err := config.Load(cmd.String("config"))
+	if err != nil {
+		return
+	}
+	if name := cmd.String("context"); name != "" {
+		cfg.Context = name
+	}
+
+	var client *vault.Client
+	if target {
+		client, err = vaultclient.NewTargetClient()
+	} else {
+		client, err = vaultclient.NewSourceClient(cfg)
+	}
+	if err != nil {
+		return
+	}
+
+	mounts, err := kv.GetSecretEngines(ctx, client)
+	if err != nil {
+		return
+	}
+
+	var names []string
+	for mountPath := range mounts {
+		names = append(names, kv.NormalizeMount(mountPath))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(cmd.Root().Writer, name)
+	}
+}
@@ -2,16 +2,52 @@
 Package secrets implements the "copy" subcommand under the "secrets" command in the vaultx CLI.
 
 The "copy" command enables users to recursively copy secrets from one Vault mount path to another,
-potentially between different Vault instances. It detects the KV (Key-Value) engine version and
-handles traversing secret paths accordingly.
+potentially between different Vault instances. It detects the KV (Key-Value) engine version of
+both mounts, traversing the source accordingly and writing in the target's format.
 
 Usage:
-  vaultx secrets copy --source-mount=<mount-path>
+  vaultx secrets copy --source-mount=<mount-path> --target-mount=<mount-path> [--since=<RFC3339>]
+  vaultx secrets copy --source-mount=<a>,<b> --target-mount=<a-copy>,<b-copy>
+  vaultx secrets copy --source-mount='kv-*' --target-mount-template='{mount}-copy'
 
 Key Features:
   - Detects KV engine version (v1 or v2)
   - Recursively traverses secret paths under the specified mount
+  - Copies several mounts in one run, each paired with the target mount at the same position
+  - Expands glob patterns in --source-mount, naming targets with --target-mount-template
   - Prepares a list of secrets for copying
+  - Optionally skips KV v2 secrets not updated since a given time (--since)
+  - Optionally places copied secrets under a path prefix in the target mount (--target-prefix)
+  - Optionally throttles Vault requests to a fixed rate (--rate-limit)
+  - Optionally limits traversal to a subpath of the source mount (--prefix)
+  - Optionally copies a source subtree to the target mount root, dropping its path (--source-path)
+  - Optionally copies a single secret, or one subtree, without traversing the mount (--path)
+  - Optionally copies exactly the secrets listed in a file, without traversing the mount (--paths-file)
+  - Optionally moves individual secrets to arbitrary target paths listed in a JSON or CSV file (--map-file, --map-only)
+  - Optionally limits how deep traversal descends (--max-depth)
+  - Optionally aborts a mount before writing any of its secrets when it holds more than expected (--max-secrets)
+  - Optionally verifies both Vaults and tokens before starting (--preflight)
+  - Optionally replays the full KV v2 version history, including deleted and destroyed versions (--all-versions)
+  - Optionally copies one pinned KV v2 version of each secret instead of the latest (--source-version)
+  - Optionally records progress so an interrupted copy can resume (--checkpoint-file)
+  - Optionally restarts an interrupted copy at a path, skipping secrets sorted before it (--resume-from)
+  - Optionally copies per-secret KV v2 retention settings (--with-metadata-config)
+  - Optionally writes a checksum manifest of every copied secret on both sides (--manifest-file)
+  - Optionally reports groups of source secrets holding identical data (--dedupe)
+  - Optionally copies only some fields of each secret (--include-keys, --exclude-keys)
+  - Optionally resolves ref:<mount>/<path>#<field> references to other secrets (--dereference)
+  - Plans every secret, target path and KV version before writing, and can print or save the plan (--plan-only, --plan-file)
+  - Optionally enables a missing target mount with the source's KV version (--create-target-mount)
+  - Skips secrets that already exist on the target unless --overwrite is passed
+  - Optionally merges source keys into existing target secrets (--merge, --merge-prefer)
+  - Optionally copies several secrets concurrently (--threads), with deterministic sorted reporting (--sort)
+  - Optionally bounds and retries slow Vault requests made for individual secrets (--timeout-per-secret)
+  - Optionally response-wraps each source read, unwrapping it only right before the write (--wrap-ttl)
+  - Optionally passes KV v2 write options such as cas through to every write (--write-options)
+  - Warns about secrets too large for Vault's storage before writing them, or fails them with --strict (--max-secret-size)
+  - Optionally writes run metrics in Prometheus text format for scheduled syncs (--metrics-file)
+  - Optionally forces KV v1 or v2 behavior when detection is unreliable (--kv-version)
+  - Translates between KV v1 and v2 when the mounts differ, or as forced by --target-kv-version
 
 This subcommand is intended for operators who need to migrate or duplicate secrets between Vault environments.
 */
@@ -19,16 +55,24 @@ This subcommand is intended for operators who need to migrate or duplicate secre
 package secrets
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault-client-go"
-	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/config"
 	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
 	"github.com/urfave/cli/v3"
 )
 
@@ -37,209 +81,668 @@ func CopyCommand() *cli.Command {
 		Name:  "copy",
 		Usage: "Copy secrets from one vault instance to another",
 		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "source-mount",
+				Usage: "KV mount to copy from, or a glob pattern such as kv-* (repeatable or comma-separated)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "target-mount",
+				Usage: "KV mount to copy to, paired with --source-mount by position (repeatable or comma-separated)",
+			},
+			&cli.StringFlag{
+				Name:  "target-mount-template",
+				Usage: "Target mount name for each source mount, with {mount} replaced by the source mount's name",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Only copy KV v2 secrets updated at or after this RFC3339 timestamp",
+			},
+			&cli.StringFlag{
+				Name:  "target-prefix",
+				Usage: "Path prefix to prepend to every secret written to the target mount",
+			},
+			&cli.StringFlag{
+				Name:  "prefix",
+				Usage: "Only traverse secrets under this path within the source mount",
+			},
+			&cli.StringFlag{
+				Name:  "source-path",
+				Usage: "Only traverse secrets under this path within the source mount, writing them relative to it at the target mount root (or --target-prefix)",
+			},
+			&cli.StringFlag{
+				Name:  "path",
+				Usage: "Copy only this secret within the source mount, or this subtree if it ends in /",
+			},
 			&cli.StringFlag{
-				Name: "source-mount",
+				Name:  "paths-file",
+				Usage: "Copy only the secrets listed in this file, one path within the source mount per line",
 			},
 			&cli.StringFlag{
-				Name: "target-mount",
+				Name:  "map-file",
+				Usage: "JSON object or two-column CSV mapping full source secret paths to paths within the target mount",
+			},
+			&cli.BoolFlag{
+				Name:  "map-only",
+				Usage: "Copy only the secrets listed in --map-file, without traversing the source mount",
+			},
+			&cli.IntFlag{
+				Name:  "max-depth",
+				Usage: "Maximum number of path levels to descend below the traversal start (0 for unlimited)",
+			},
+			&cli.IntFlag{
+				Name:  "max-secrets",
+				Usage: "Abort once more than this many secrets are found, as a guard against targeting the wrong mount (0 for unlimited)",
+			},
+			&cli.StringFlag{
+				Name:  "resume-from",
+				Usage: "Skip every secret whose path within the source mount sorts before this one, to restart an interrupted copy by hand",
+			},
+			&cli.StringFlag{
+				Name:  "checkpoint-file",
+				Usage: "Record completed secrets to this file and skip them when restarting",
+			},
+			&cli.BoolFlag{
+				Name:  "all-versions",
+				Usage: "Copy every KV v2 version, preserving deleted and destroyed state",
+			},
+			&cli.IntFlag{
+				Name:  "source-version",
+				Usage: "Copy this KV v2 version of each secret instead of the latest, skipping secrets without it",
+			},
+			&cli.BoolFlag{
+				Name:  "with-metadata-config",
+				Usage: "Copy each KV v2 secret's max_versions, cas_required and delete_version_after settings",
+			},
+			&cli.StringFlag{
+				Name:  "manifest-file",
+				Usage: "Write a JSON manifest of source and target checksums for every copied secret to this file",
+			},
+			&cli.BoolFlag{
+				Name:  "merge",
+				Usage: "Merge source keys into existing target secrets instead of skipping or replacing them",
+			},
+			&cli.StringFlag{
+				Name:  "merge-prefer",
+				Value: "source",
+				Usage: "Which value wins when --merge finds a key on both sides: source or target",
+			},
+			&cli.StringSliceFlag{
+				Name:  "include-keys",
+				Usage: "Copy only these fields of each secret (repeatable or comma-separated)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude-keys",
+				Usage: "Drop these fields from each secret before writing it (repeatable or comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "dereference",
+				Usage: "Replace ref:<mount>/<path>#<field> values with the referenced field's value before writing",
+			},
+			&cli.BoolFlag{
+				Name:  "plan-only",
+				Usage: "Print the copy plan (secrets, target paths and KV versions) as JSON without copying",
+			},
+			&cli.StringFlag{
+				Name:  "plan-file",
+				Usage: "Save the copy plan as JSON to this file before copying",
+			},
+			&cli.BoolFlag{
+				Name:  "create-target-mount",
+				Usage: "Enable a missing target mount as a KV engine of the source's version before copying",
+			},
+			&cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "Replace secrets that already exist instead of skipping them",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Abort instead of warning when the target token lacks write capability, and fail secrets over --max-secret-size",
+			},
+			maxSecretSizeFlag(),
+			dedupeFlag(),
+			&cli.BoolFlag{
+				Name:  "preflight",
+				Usage: "Check health, token TTL and capabilities on source and target before copying",
+			},
+			&cli.FloatFlag{
+				Name:  "rate-limit",
+				Usage: "Maximum Vault requests per second (0 for unlimited)",
+			},
+			&cli.IntFlag{
+				Name:  "threads",
+				Value: 1,
+				Usage: "Number of secrets to copy concurrently",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout-per-secret",
+				Usage: "Bound each Vault request made to copy a secret, not counting rate-limit waits, retrying requests that exceed it (0 for no limit)",
+			},
+			&cli.DurationFlag{
+				Name:  "wrap-ttl",
+				Usage: "Response-wrap each source read with this TTL and unwrap it only right before the secret is processed and written (0 disables wrapping)",
+			},
+			&cli.BoolFlag{
+				Name:  "sort",
+				Usage: "Log a final per-secret result for every secret in sorted path order",
+			},
+			kvVersionFlag(),
+			writeOptionsFlag(),
+			metricsFileFlag(),
+			&cli.StringFlag{
+				Name:  "target-kv-version",
+				Usage: "Force how secrets are written to the target mount (1 or 2), translating between versions if needed",
 			},
 		},
+		ShellComplete: completeMounts,
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			if err := ValidateFlags(cmd); err != nil {
-				return err
+			// a run that fails validation, e.g. on a sealed target, still replaces the
+			// metrics file, so the previous run's success doesn't hide it
+			start := time.Now()
+			var result *kv.CopyResult
+			sourceMounts, targetMounts, err := ValidateFlags(ctx, cmd)
+			if err == nil {
+				result, err = CopySecrets(ctx, cmd, sourceMounts, targetMounts)
 			}
-			CopySecrets(ctx, cmd)
-			return nil
+			if metricsFile := cmd.String("metrics-file"); metricsFile != "" {
+				if metricsErr := writeCopyMetrics(metricsFile, result, time.Since(start), err); metricsErr != nil {
+					slog.Error("failed to write metrics file", "path", metricsFile, "error", metricsErr)
+				}
+			}
+			if result != nil {
+				operation := "copy finished"
+				counts := []color.Count{
+					{Label: "copied", N: len(result.Copied), Paint: color.Green},
+					{Label: "skipped", N: len(result.Skipped), Paint: color.Yellow},
+					{Label: "failed", N: len(result.Failed), Paint: color.Red},
+				}
+				if ctx.Err() != nil {
+					operation = "copy interrupted"
+					counts = append(counts, color.Count{Label: "not started", N: len(result.NotStarted), Paint: color.Yellow})
+				}
+				fmt.Println(color.Summary(operation, counts...))
+				if len(result.Skipped) > 0 && !cmd.Bool("overwrite") && !cmd.Bool("merge") {
+					slog.Info("secrets that already exist on the target are skipped; pass --overwrite to replace them")
+				}
+			}
+			return err
 		},
 	}
 }
 
-func ValidateFlags(cmd *cli.Command) error {
-	// Validate --source-mount flag
-	sourceMount := cmd.String("source-mount")
-	if sourceMount == "" {
-		slog.Error("--source-mount flag is required")
-		os.Exit(1)
+// ValidateFlags checks the copy flags and that the mounts they name exist, and returns
+// the source mounts to copy, with patterns expanded, and the target mount each is copied
+// to.
+func ValidateFlags(ctx context.Context, cmd *cli.Command) ([]string, []string, error) {
+	// Validate --source-mount flag, falling back to the config file's default-mount
+	if defaultMount := config.FromContext(ctx).DefaultMount; len(cmd.StringSlice("source-mount")) == 0 && defaultMount != "" {
+		if err := cmd.Set("source-mount", defaultMount); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(cmd.StringSlice("source-mount")) == 0 {
+		return nil, nil, errors.New("--source-mount flag is required")
 	}
 
-	// Validate --target-mount flag
-	targetMount := cmd.String("target-mount")
-	if targetMount == "" {
-		slog.Error("--target-mount flag is required")
-		os.Exit(1)
+	// Validate --target-mount and --target-mount-template flags; each source mount is
+	// copied to the target mount at the same position, or to the name the template
+	// gives it
+	template := cmd.String("target-mount-template")
+	if len(cmd.StringSlice("target-mount")) == 0 && template == "" {
+		return nil, nil, errors.New("--target-mount or --target-mount-template flag is required")
+	}
+	if len(cmd.StringSlice("target-mount")) > 0 && template != "" {
+		return nil, nil, errors.New("--target-mount and --target-mount-template cannot be used together")
+	}
+	if template != "" && !strings.Contains(template, mountPlaceholder) {
+		return nil, nil, fmt.Errorf("--target-mount-template must contain the %s placeholder (got %q)", mountPlaceholder, template)
 	}
 
-	return nil
-}
+	sourceMounts, targetMounts, err := mountPairs(ctx, cmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid mounts: %w", err)
+	}
+
+	// Validate --since flag
+	if since := cmd.String("since"); since != "" {
+		if _, err := time.Parse(time.RFC3339, since); err != nil {
+			return nil, nil, fmt.Errorf("--since must be an RFC3339 timestamp (got %q): %w", since, err)
+		}
+	}
+
+	// Validate --path flag; a trailing slash selects a subtree
+	if secretPath := cmd.String("path"); secretPath != "" {
+		if len(sourceMounts) > 1 {
+			return nil, nil, errors.New("--path cannot be used with more than one --source-mount")
+		}
+		if cmd.String("prefix") != "" {
+			return nil, nil, errors.New("--path and --prefix cannot be used together")
+		}
+		if strings.Trim(secretPath, "/") == "" {
+			return nil, nil, fmt.Errorf("--path must name a secret or subtree within the source mount (got %q)", secretPath)
+		}
+	}
 
-func GetSourceMountVersion(ctx context.Context, cmd *cli.Command) (string, error) {
-	client := vaultclient.GetVaultClient(ctx)
+	// Validate --source-path flag
+	if sourcePath := cmd.String("source-path"); sourcePath != "" {
+		if len(sourceMounts) > 1 {
+			return nil, nil, errors.New("--source-path cannot be used with more than one --source-mount")
+		}
+		if cmd.String("path") != "" || cmd.String("prefix") != "" || cmd.String("paths-file") != "" {
+			return nil, nil, errors.New("--source-path cannot be used with --path, --prefix or --paths-file")
+		}
+		if strings.Trim(sourcePath, "/") == "" {
+			return nil, nil, fmt.Errorf("--source-path must name a subtree within the source mount (got %q)", sourcePath)
+		}
+	}
 
-	sourceMount := cmd.String("source-mount")
+	// Validate --paths-file flag
+	if file := cmd.String("paths-file"); file != "" {
+		if len(sourceMounts) > 1 {
+			return nil, nil, errors.New("--paths-file cannot be used with more than one --source-mount")
+		}
+		if cmd.String("path") != "" || cmd.String("prefix") != "" {
+			return nil, nil, errors.New("--paths-file cannot be used with --path or --prefix")
+		}
+		if _, err := readPathsFile(file); err != nil {
+			return nil, nil, fmt.Errorf("invalid --paths-file %q: %w", file, err)
+		}
+	}
 
-	if !strings.HasSuffix(sourceMount, "/") {
-		sourceMount += "/"
+	// Validate --map-file and --map-only flags
+	if file := cmd.String("map-file"); file != "" {
+		if _, err := readMapFile(file); err != nil {
+			return nil, nil, fmt.Errorf("invalid --map-file %q: %w", file, err)
+		}
+	} else if cmd.Bool("map-only") {
+		return nil, nil, errors.New("--map-only requires --map-file")
+	}
+	if cmd.Bool("map-only") && (cmd.String("path") != "" || cmd.String("prefix") != "" || cmd.String("source-path") != "" || cmd.String("paths-file") != "") {
+		return nil, nil, errors.New("--map-only cannot be used with --path, --prefix, --source-path or --paths-file")
 	}
 
-	response, err := client.System.MountsListSecretsEngines(ctx)
-	if err != nil {
-		slog.Error("Failed to list secret engines", "error", err)
+	// Validate --resume-from flag
+	if resumeFrom := cmd.String("resume-from"); resumeFrom != "" {
+		if len(sourceMounts) > 1 {
+			return nil, nil, errors.New("--resume-from cannot be used with more than one --source-mount")
+		}
+		if strings.Trim(resumeFrom, "/") == "" {
+			return nil, nil, fmt.Errorf("--resume-from must name a secret path within the source mount (got %q)", resumeFrom)
+		}
 	}
 
-	version := fmt.Sprintf("%v", response.Data[sourceMount].(map[string]interface{})["options"].(map[string]interface{})["version"])
+	// Validate --source-version flag
+	if version := cmd.Int("source-version"); version < 0 {
+		return nil, nil, fmt.Errorf("--source-version must be a positive version number (got %d)", version)
+	} else if version > 0 && cmd.Bool("all-versions") {
+		return nil, nil, errors.New("--source-version and --all-versions cannot be used together")
+	}
 
-	return version, err
-}
+	// Validate --merge-prefer flag
+	if prefer := cmd.String("merge-prefer"); prefer != "source" && prefer != "target" {
+		return nil, nil, fmt.Errorf("--merge-prefer must be source or target (got %q)", prefer)
+	}
 
-func ListSecrets(ctx context.Context, cmd *cli.Command) ([]string, error) {
-	client := vaultclient.GetVaultClient(ctx)
-	sourceMount := cmd.String("source-mount")
+	// Validate --threads flag
+	if threads := cmd.Int("threads"); threads < 1 {
+		return nil, nil, fmt.Errorf("--threads must be at least 1 (got %d)", threads)
+	}
 
-	kvVersion, err := GetSourceMountVersion(ctx, cmd)
+	// Validate --kv-version flag
+	kvVersion, err := kvVersionOverride(cmd)
 	if err != nil {
-		slog.Error("Failed to get source mount version", "error", err)
-		return nil, err
+		return nil, nil, fmt.Errorf("invalid --kv-version: %w", err)
+	}
+	if _, err := writeOptions(cmd); err != nil {
+		return nil, nil, fmt.Errorf("invalid --write-options: %w", err)
+	}
+	if _, err := targetKVVersionOverride(cmd); err != nil {
+		return nil, nil, fmt.Errorf("invalid --target-kv-version: %w", err)
+	}
+	if kvVersion != "" {
+		// the override exists for mounts whose metadata can't be trusted, so don't
+		// second-guess them here either
+		return sourceMounts, targetMounts, nil
 	}
 
-	var secretsList []string
+	// Confirm both mounts exist and are KV engines, so a typo fails here rather than
+	// as a 404 somewhere in the traversal.
+	for _, sourceMount := range sourceMounts {
+		if _, err := kv.LookupKVMount(ctx, vaultclient.GetVaultClient(ctx), sourceMount); err != nil {
+			return nil, nil, fmt.Errorf("invalid --source-mount: %w", err)
+		}
+	}
 
-	var traverse func(string) error
-	traverse = func(currentPath string) error {
+	targetClient, err := vaultclient.NewTargetClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize target vault client: %w", err)
+	}
+	if err := vaultclient.CheckSealed(ctx, targetClient, "target"); err != nil {
+		return nil, nil, err
+	}
+	for _, targetMount := range targetMounts {
+		_, err := kv.LookupKVMount(ctx, targetClient, targetMount)
+		if errors.Is(err, kv.ErrMountNotFound) && cmd.Bool("create-target-mount") {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --target-mount: %w", err)
+		}
+	}
 
-		var keys []string
+	return sourceMounts, targetMounts, nil
+}
 
-		switch kvVersion {
-		case "1":
-			response, err := client.Secrets.KvV1List(ctx, currentPath, vault.WithMountPath(sourceMount))
-			if err != nil {
-				if strings.Contains(err.Error(), "404") {
-					slog.Error("404 Not Found at:", "path", currentPath)
-					return nil
-				}
-				return fmt.Errorf("kv v1 list failed at path %q: %w", currentPath, err)
-			}
-			keys = response.Data.Keys
-
-		case "2":
-			response, err := client.Secrets.KvV2List(ctx, currentPath, vault.WithMountPath(sourceMount))
-			if err != nil {
-				if strings.Contains(err.Error(), "404") {
-					slog.Error("404 Not Found at:", "path", currentPath)
-					return nil
-				}
-				return fmt.Errorf("kv v2 list failed at path %q: %w", currentPath, err)
-			}
-			keys = response.Data.Keys
+// warnIgnoredFlags warns about the flags given that don't take effect for plan. The
+// library explains why in terms of the CopyOptions fields; this names the flags.
+func warnIgnoredFlags(cmd *cli.Command, plan *kv.CopyPlan, writeOptions bool) {
+	flags := []struct {
+		name    string
+		ignored bool
+	}{
+		{"--all-versions", cmd.Bool("all-versions") && !plan.AllVersions},
+		{"--with-metadata-config", cmd.Bool("with-metadata-config") && !plan.WithMetadataConfig},
+		{"--since", cmd.String("since") != "" && plan.SourceVersion != "2"},
+		{"--write-options", writeOptions && plan.TargetVersion != "2"},
+	}
+	for _, flag := range flags {
+		if flag.ignored {
+			slog.Warn("flag has no effect on this mount pair", "flag", flag.name, "source_mount", plan.SourceMount, "target_mount", plan.TargetMount)
+		}
+	}
+}
 
-		default:
-			return fmt.Errorf("unsupported kv version: %s", kvVersion)
+// mountPlaceholder is replaced by each source mount's name in --target-mount-template.
+const mountPlaceholder = "{mount}"
+
+// mountPairs returns the source mounts to copy, with glob patterns such as "kv-*"
+// expanded against the source's KV mounts, and the target mount each is copied to.
+func mountPairs(ctx context.Context, cmd *cli.Command) ([]string, []string, error) {
+	var sourceMounts []string
+	for _, mount := range cmd.StringSlice("source-mount") {
+		if !strings.ContainsAny(mount, "*?[") {
+			sourceMounts = append(sourceMounts, mount)
+			continue
 		}
 
-		for _, key := range keys {
-			full := path.Join(currentPath, key)
-			if strings.HasSuffix(key, "/") {
-				if err := traverse(full); err != nil {
-					return err
-				}
-			} else {
-				finalPath := path.Join(sourceMount, full)
-				secretsList = append(secretsList, finalPath)
-			}
+		matches, err := matchMounts(ctx, vaultclient.GetVaultClient(ctx), mount)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("no KV mount matches %q", mount)
 		}
+		slog.Info("expanded mount pattern", "pattern", mount, "mounts", matches)
+		sourceMounts = append(sourceMounts, matches...)
+	}
 
-		return nil
+	if template := cmd.String("target-mount-template"); template != "" {
+		targetMounts := make([]string, len(sourceMounts))
+		for i, mount := range sourceMounts {
+			targetMounts[i] = strings.ReplaceAll(template, mountPlaceholder, kv.NormalizeMount(mount))
+		}
+		return sourceMounts, targetMounts, nil
 	}
 
-	if err := traverse(""); err != nil {
+	targetMounts := cmd.StringSlice("target-mount")
+	if len(targetMounts) != len(sourceMounts) {
+		return nil, nil, fmt.Errorf("%d source mounts but %d target mounts; give one --target-mount per source mount, or use --target-mount-template", len(sourceMounts), len(targetMounts))
+	}
+	return sourceMounts, targetMounts, nil
+}
+
+// matchMounts returns the names of the KV mounts on client matching the glob pattern,
+// sorted. Trailing slashes on the pattern are ignored.
+func matchMounts(ctx context.Context, client *vault.Client, pattern string) ([]string, error) {
+	pattern = strings.Trim(pattern, "/")
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid mount pattern %q: %w", pattern, err)
+	}
+
+	mounts, err := kv.GetSecretEngines(ctx, client)
+	if err != nil {
 		return nil, err
 	}
 
-	return secretsList, nil
+	var matches []string
+	for mountPath := range mounts {
+		name := kv.NormalizeMount(mountPath)
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
 }
 
-// Read and Create secret
-func CopySecrets(ctx context.Context, cmd *cli.Command) error {
+// CopySecrets translates the copy flags into options for the library and copies each
+// source mount to the target mount at the same position, as returned by ValidateFlags,
+// from the source client in ctx to the target client from the environment. Each pair is
+// planned with PlanCopy and the plan then executed, unless --plan-only is set.
+//
+// The returned result combines the results of every mount pair copied so far, so it
+// reports partial progress even when a later pair fails. It is nil with --plan-only.
+func CopySecrets(ctx context.Context, cmd *cli.Command, sourceMounts, targetMounts []string) (*kv.CopyResult, error) {
 	sourceClient := vaultclient.GetVaultClient(ctx)
+	if sourceClient == nil {
+		return nil, vaultclient.ErrVaultClientMissing
+	}
 
-	targetAddr := os.Getenv("VAULT_TARGET_ADDR")
-	targetToken := os.Getenv("VAULT_TARGET_TOKEN")
-
-	if targetAddr == "" || targetToken == "" {
-		slog.Error("VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN environment variables are required")
-		os.Exit(1)
+	targetClient, err := vaultclient.NewTargetClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize target vault client: %w", err)
 	}
 
-	targetClient, _ := vault.New(
-		vault.WithAddress(targetAddr),
-	)
+	var since time.Time
+	if raw := cmd.String("since"); raw != "" {
+		since, _ = time.Parse(time.RFC3339, raw)
+	}
 
-	if err := targetClient.SetToken(targetToken); err != nil {
-		slog.Error("Failed to initialize target vault client", "error", err)
+	var paths []string
+	if file := cmd.String("paths-file"); file != "" {
+		if paths, err = readPathsFile(file); err != nil {
+			return nil, err
+		}
 	}
 
-	sourceMount := cmd.String("source-mount")
-	targetMount := cmd.String("target-mount")
+	var pathMap map[string]string
+	if file := cmd.String("map-file"); file != "" {
+		if pathMap, err = readMapFile(file); err != nil {
+			return nil, err
+		}
+	}
 
-	kvVersion, err := GetSourceMountVersion(ctx, cmd)
-	if err != nil {
-		slog.Error("failed to detect source mount version", "error", err)
-		os.Exit(1)
+	var manifest *kv.Manifest
+	if cmd.String("manifest-file") != "" {
+		manifest = &kv.Manifest{}
+	}
+	var duplicates *kv.DuplicateIndex
+	if cmd.Bool("dedupe") && !cmd.Bool("plan-only") {
+		duplicates = &kv.DuplicateIndex{}
+		defer func() { reportDuplicates(duplicates) }()
 	}
 
-	secretsList, err := ListSecrets(ctx, cmd)
+	var plans []*kv.CopyPlan
+	var result *kv.CopyResult
+	if !cmd.Bool("plan-only") {
+		result = &kv.CopyResult{Errors: map[string]error{}}
+	}
+	options, err := writeOptions(cmd)
 	if err != nil {
-		slog.Error("failed to list secrets under source mount", "error", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	for _, fullPath := range secretsList {
-		relativePath := strings.TrimPrefix(fullPath, strings.TrimSuffix(sourceMount, "/")+"/")
+	for i, sourceMount := range sourceMounts {
+		if len(sourceMounts) > 1 {
+			slog.Info("copying mount", "source_mount", sourceMount, "target_mount", targetMounts[i])
+		}
 
-		switch kvVersion {
-		case "1":
-			secret, err := sourceClient.Secrets.KvV1Read(ctx, relativePath, vault.WithMountPath(sourceMount))
-			if err != nil {
-				slog.Error("failed to read KV v1 secret", "path", fullPath, "error", err)
-				continue
-			}
+		opts := kv.CopyOptions{
+			SourceMount:        sourceMount,
+			TargetMount:        targetMounts[i],
+			TargetPrefix:       cmd.String("target-prefix"),
+			Prefix:             cmd.String("prefix"),
+			SourcePath:         cmd.String("source-path"),
+			Path:               cmd.String("path"),
+			Paths:              paths,
+			PathMap:            pathMap,
+			MapOnly:            cmd.Bool("map-only"),
+			MaxDepth:           cmd.Int("max-depth"),
+			MaxSecrets:         cmd.Int("max-secrets"),
+			Since:              since,
+			AllVersions:        cmd.Bool("all-versions"),
+			SourceVersion:      cmd.Int("source-version"),
+			Strict:             cmd.Bool("strict"),
+			MaxSecretSize:      cmd.Int("max-secret-size"),
+			Preflight:          cmd.Bool("preflight"),
+			RateLimit:          cmd.Float("rate-limit"),
+			CheckpointFile:     cmd.String("checkpoint-file"),
+			ResumeFrom:         cmd.String("resume-from"),
+			KVVersion:          cmd.String("kv-version"),
+			TargetKVVersion:    cmd.String("target-kv-version"),
+			Overwrite:          cmd.Bool("overwrite"),
+			Threads:            cmd.Int("threads"),
+			WithMetadataConfig: cmd.Bool("with-metadata-config"),
+			Sort:               cmd.Bool("sort"),
+			Manifest:           manifest,
+			Duplicates:         duplicates,
+			Merge:              cmd.Bool("merge"),
+			MergePreferTarget:  cmd.String("merge-prefer") == "target",
+			TimeoutPerSecret:   cmd.Duration("timeout-per-secret"),
+			WrapTTL:            cmd.Duration("wrap-ttl"),
+			CreateTargetMount:  cmd.Bool("create-target-mount"),
+			IncludeKeys:        cmd.StringSlice("include-keys"),
+			ExcludeKeys:        cmd.StringSlice("exclude-keys"),
+			Dereference:        cmd.Bool("dereference"),
+			WriteOptions:       options,
+		}
 
-			if secret.Data == nil {
-				slog.Warn("no data found at KV v1 secret", "path", fullPath)
+		plan, err := kv.PlanCopy(ctx, sourceClient, targetClient, opts)
+		if errors.Is(err, kv.ErrSecretLimit) {
+			return result, fmt.Errorf("copy from %q to %q aborted before writing any of its secrets: %w; pass a higher --max-secrets if the mount is expected to be this large", sourceMount, targetMounts[i], err)
+		}
+		if err != nil {
+			return result, fmt.Errorf("copy from %q to %q failed: %w", sourceMount, targetMounts[i], err)
+		}
+		plans = append(plans, plan)
+		slog.Info("copy planned", "source_mount", sourceMount, "target_mount", targetMounts[i], "secrets", len(plan.Items))
+		warnIgnoredFlags(cmd, plan, len(options) > 0)
+
+		// save each plan before executing it, so it can be reviewed even if the copy fails
+		if file := cmd.String("plan-file"); file != "" {
+			if err := writeJSONFile(file, plans); err != nil {
+				return nil, fmt.Errorf("failed to write plan: %w", err)
 			}
+		}
+		if cmd.Bool("plan-only") {
+			continue
+		}
 
-			_, err = targetClient.Secrets.KvV1Write(ctx, relativePath, secret.Data, vault.WithMountPath(targetMount))
-			if err != nil {
-				slog.Error("failed to write KV v1 secret to target mount", "path", relativePath, "error", err)
-				continue
+		pairResult, err := kv.ExecuteCopyPlan(ctx, sourceClient, targetClient, plan, opts)
+		if pairResult != nil {
+			result.Copied = append(result.Copied, pairResult.Copied...)
+			result.Skipped = append(result.Skipped, pairResult.Skipped...)
+			result.Failed = append(result.Failed, pairResult.Failed...)
+			result.NotStarted = append(result.NotStarted, pairResult.NotStarted...)
+			for secretPath, err := range pairResult.Errors {
+				result.Errors[secretPath] = err
 			}
+		}
+		if err != nil {
+			return result, fmt.Errorf("copy from %q to %q failed: %w", sourceMount, targetMounts[i], err)
+		}
+	}
 
-			slog.Info("successfully copied KV v1 secret", "path", relativePath)
+	if cmd.Bool("plan-only") && cmd.String("plan-file") == "" {
+		out, err := json.MarshalIndent(plans, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println(string(out))
+	}
 
-		case "2":
-			secret, err := sourceClient.Secrets.KvV2Read(ctx, relativePath, vault.WithMountPath(sourceMount))
-			if err != nil {
-				slog.Error("failed to read KV v2 secret", "path", fullPath, "error", err)
-			}
+	if manifest != nil && !cmd.Bool("plan-only") {
+		if err := manifest.WriteFile(cmd.String("manifest-file")); err != nil {
+			return result, fmt.Errorf("failed to write manifest: %w", err)
+		}
+		slog.Info("manifest written", "file", cmd.String("manifest-file"), "secrets", len(manifest.Entries()))
+	}
 
-			if secret.Data.Data == nil {
-				slog.Warn("no data found at KV v2 secret", "path", fullPath)
-			}
+	return result, nil
+}
 
-			req := schema.KvV2WriteRequest{
-				Data: secret.Data.Data,
-			}
-			_, err = targetClient.Secrets.KvV2Write(ctx, relativePath, req, vault.WithMountPath(targetMount))
-			if err != nil {
-				slog.Error("failed to write KV v2 secret to target mount", "path", relativePath, "error", err)
-				continue
-			}
-			slog.Info("copied KV v2 secret", "path", relativePath)
+// readPathsFile reads the newline-delimited secret paths in file. Blank lines and lines
+// starting with # are ignored. Every path must name a single secret, not a subtree.
+func readPathsFile(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
 
-		default:
-			slog.Error("unsupported KV version", "version", kvVersion)
-			return fmt.Errorf("unsupported KV version: %s", kvVersion)
+	var paths []string
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		if strings.HasSuffix(line, "/") || strings.Trim(line, "/") == "" {
+			return nil, fmt.Errorf("line %d: %q does not name a single secret", i+1, line)
+		}
+		paths = append(paths, strings.Trim(line, "/"))
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no secret paths listed")
 	}
+	return paths, nil
+}
 
-	return nil
+// readMapFile reads a map from full source secret paths to target paths within the
+// target mount. A .csv file holds one "source,target" pair per row, optionally after a
+// "source,target" header row; any other file must be a JSON object of strings. Both
+// sides must name a single secret, and each source may appear only once.
+func readMapFile(file string) (map[string]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs [][]string
+	if strings.EqualFold(path.Ext(file), ".csv") {
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.FieldsPerRecord = 2
+		reader.TrimLeadingSpace = true
+		reader.Comment = '#'
+		if pairs, err = reader.ReadAll(); err != nil {
+			return nil, err
+		}
+		if len(pairs) > 0 && strings.EqualFold(pairs[0][0], "source") && strings.EqualFold(pairs[0][1], "target") {
+			pairs = pairs[1:]
+		}
+	} else {
+		var object map[string]string
+		if err := json.Unmarshal(content, &object); err != nil {
+			return nil, fmt.Errorf("expected a JSON object of source path to target path: %w", err)
+		}
+		for source, target := range object {
+			pairs = append(pairs, []string{source, target})
+		}
+	}
+
+	pathMap := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		source, target := strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+		for _, p := range []string{source, target} {
+			if strings.HasSuffix(p, "/") || strings.Trim(p, "/") == "" {
+				return nil, fmt.Errorf("%q does not name a single secret", p)
+			}
+		}
+		source = strings.Trim(source, "/")
+		if _, ok := pathMap[source]; ok {
+			return nil, fmt.Errorf("%q is mapped more than once", source)
+		}
+		pathMap[source] = strings.Trim(target, "/")
+	}
+	if len(pathMap) == 0 {
+		return nil, errors.New("no paths mapped")
+	}
+	return pathMap, nil
 }
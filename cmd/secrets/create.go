@@ -1,20 +1,45 @@
 /*
 Package secrets implements the "create" subcommand under the "secrets" command in the vaultx CLI.
 
-The "create" command allows users to create secrets in a Vault instance from a structured JSON file.
+The "create" command allows users to create secrets in a Vault instance from a structured JSON or YAML file.
+Alternatively, --from-dir reads a directory tree with one file per secret, as written by
+"export --output-dir", and writes each file to the path derived from its location under --mount.
 It supports both KV engine versions v1 and v2, and automatically detects the appropriate engine and mount path
 for each secret based on the Vault server configuration.
 
 Usage:
-  vaultx secrets create --from-file=<path-to-file.json>
+  vaultx secrets create --from-file=<path-to-file.json> [--format=auto|json|yaml] [--decrypt=sops]
+  vaultx secrets create --from-dir=<directory> --mount=<mount-path>
+  vaultx secrets create --from-vault=<mount>/<path> [--mount=<mount-path>]
 
 Flags:
-  --from-file, -f   Path to the JSON file containing secret key/value pairs.
+  --from-file, -f       Path to the JSON or YAML file containing secret key/value pairs, or - for stdin.
+  --from-dir            Directory of per-secret files; hidden files and directories are skipped.
+  --from-vault          Seed from the secrets under this path on the source Vault (VAULT_ADDR/VAULT_TOKEN),
+                        writing them to the target Vault (VAULT_TARGET_ADDR/VAULT_TARGET_TOKEN).
+  --mount               The mount --from-dir secrets are written under; with --from-vault, replaces the source mount.
+  --no-interpolate      Keep ${VAR} placeholders in values instead of substituting environment variables.
+                        Values read with --from-vault are never substituted.
+  --allow-unset         Substitute an empty string for unset variables instead of failing.
+  --format              Input format: json, yaml, or auto (by extension, then by content).
+  --decrypt             Decrypt --from-file or --from-dir input before parsing it; only "sops" is supported.
+  --rate-limit          Maximum Vault requests per second (0 for unlimited).
+  --kv-version          Force KV v1 or v2 behavior instead of detecting it from the mount.
+  --write-options       KV v2 write options as key=value pairs, e.g. cas=0 to never replace a secret.
+  --strict              Abort instead of warning when the token lacks write capability or a secret is too large.
+  --max-secret-size     Size in bytes above which a secret is reported before writing (default 512 KiB, 0 disables).
+  --overwrite           Replace secrets that already exist instead of skipping them.
+  --only-changed        Write only new secrets and those whose data differs from Vault's.
+  --base64-encode-keys  Base64-encode the values of these fields in every secret before writing.
+  --validate-only       Check the input, including mount resolution, and report every problem without writing.
 
 Key Features:
-  - Parses secret data from a user-provided JSON file
-	- Supports both KV v1 and KV v2 engines
+  - Parses secret data from a user-provided JSON or YAML file
+  - Supports both KV v1 and KV v2 engines
   - Automatically detects KV engine version and mount path
+  - Substitutes ${VAR} placeholders in values from the environment
+  - Decrypts SOPS-encrypted input files with the sops binary (--decrypt sops)
+  - Seeds a target Vault from a subtree of the source Vault (--from-vault)
   - Intended for use in bootstrapping or automation scenarios involving Vault
 
 This subcommand is ideal for quickly importing predefined secrets into a Vault instance.
@@ -23,24 +48,31 @@ This subcommand is ideal for quickly importing predefined secrets into a Vault i
 package secrets
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/hashicorp/vault-client-go"
-	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/color"
 	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
 	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
 )
 
-type MountInfo struct {
-	MountPath string
-	Version   string // "v1" or "v2"
-}
-
 func CreateCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "create",
@@ -50,166 +82,448 @@ func CreateCommand() *cli.Command {
 				Name:    "from-file",
 				Aliases: []string{"f"},
 			},
-			// TODO: add --skip-existing flag
-			// &cli.StringFlag{
-			// 	Name:    "skip-existing",
-			// },
+			&cli.StringFlag{
+				Name:  "from-dir",
+				Usage: "Directory of per-secret JSON or YAML files, as written by export --output-dir",
+			},
+			&cli.StringFlag{
+				Name:  "from-vault",
+				Usage: "Read the secrets under this <mount>/<path> on the source Vault and write them to the target Vault (VAULT_TARGET_ADDR/VAULT_TARGET_TOKEN)",
+			},
+			&cli.StringFlag{
+				Name:  "mount",
+				Usage: "Mount to write --from-dir secrets under, or --from-vault secrets to instead of their source mount",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "auto",
+				Usage: "Input format: json, yaml, or auto to detect from the file extension and content",
+			},
+			&cli.StringFlag{
+				Name:  "decrypt",
+				Usage: "Decrypt input files before parsing them: sops runs \"sops --decrypt\" on each file",
+			},
+			&cli.BoolFlag{
+				Name:  "no-interpolate",
+				Usage: "Do not substitute ${VAR} placeholders in values from the environment",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-unset",
+				Usage: "Substitute an empty string for ${VAR} placeholders whose variable is unset",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Abort instead of warning when the token lacks write capability or a secret is over --max-secret-size",
+			},
+			maxSecretSizeFlag(),
+			&cli.FloatFlag{
+				Name:  "rate-limit",
+				Usage: "Maximum Vault requests per second (0 for unlimited)",
+			},
+			kvVersionFlag(),
+			writeOptionsFlag(),
+			&cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "Replace secrets that already exist instead of skipping them",
+			},
+			&cli.BoolFlag{
+				Name:  "only-changed",
+				Usage: "Write only secrets that are new or whose data differs from what Vault stores",
+			},
+			&cli.StringSliceFlag{
+				Name:  "base64-encode-keys",
+				Usage: "Base64-encode the values of these fields in every secret before writing (repeatable or comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "validate-only",
+				Usage: "Validate the input, including mount resolution for every path, and report all problems without writing",
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return CreateSecrets(ctx, cmd)
+			result, err := CreateSecrets(ctx, cmd)
+			if result != nil {
+				operation := "create finished"
+				counts := []color.Count{
+					{Label: "written", N: len(result.Written), Paint: color.Green},
+					{Label: "skipped", N: len(result.Skipped), Paint: color.Yellow},
+					{Label: "failed", N: len(result.Failed), Paint: color.Red},
+				}
+				if ctx.Err() != nil {
+					operation = "create interrupted"
+					counts = append(counts, color.Count{Label: "not started", N: len(result.NotStarted), Paint: color.Yellow})
+				}
+				fmt.Println(color.Summary(operation, counts...))
+				if len(result.Skipped) > 0 && !cmd.Bool("overwrite") && !cmd.Bool("only-changed") {
+					slog.Info("secrets that already exist are skipped; pass --overwrite to replace them")
+				}
+			}
+			return err
 		},
 	}
 }
 
-// CreateSecrets reads a structured JSON file containing secrets and writes them to a Vault instance.
+// CreateSecrets reads a structured JSON or YAML file containing secrets, or a directory
+// of per-secret files, and writes them to a Vault instance.
 //
-// The function supports both KV v1 and KV v2 secret engines, automatically determining the correct
-// mount and version for each secret path based on the enabled secret engines in Vault.
-//
-// For each secret, it computes the appropriate mount and relative path, and then writes the data
-// to Vault. KV v2 secrets are versioned automatically; KV v1 secrets are overwritten directly.
-//
-// This function is typically used for bootstrapping secrets in automation workflows.
-// It will overwrite existing secrets without prompting. Secrets with an unknown or unsupported
-// engine version will be skipped and logged.
-func CreateSecrets(ctx context.Context, cmd *cli.Command) error {
+// The file maps full secret paths, including the mount, to the key/value data to store
+// there. The secrets are written by the library's CreateSecrets, which detects the mount
+// and KV version for each path; see its documentation for the write semantics.
+func CreateSecrets(ctx context.Context, cmd *cli.Command) (*kv.CreateResult, error) {
 	client := vaultclient.GetVaultClient(ctx)
 	if client == nil {
-		return errors.New("vault client not found in context")
+		return nil, vaultclient.ErrVaultClientMissing
 	}
 
-	// validate --from-file flag
-	filePath := cmd.String("from-file")
-	if filePath == "" {
-		slog.Error("--from-file flag is required")
-		os.Exit(1)
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return nil, err
 	}
-
-	raw, err := os.ReadFile(filePath)
+	options, err := writeOptions(cmd)
 	if err != nil {
-		slog.Error("failed to load file", "error", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	// load JSON
+	filePath := cmd.String("from-file")
+	dir := cmd.String("from-dir")
+	from := cmd.String("from-vault")
+
 	var secrets map[string]map[string]interface{}
-	if err := json.Unmarshal(raw, &secrets); err != nil {
-		slog.Error("invalid JSON structure", "error", err)
-		os.Exit(1)
+	switch {
+	case len(slices.DeleteFunc([]string{filePath, dir, from}, func(s string) bool { return s == "" })) > 1:
+		return nil, errors.New("only one of --from-file, --from-dir and --from-vault can be used")
+	case from != "" && cmd.String("decrypt") != "":
+		return nil, errors.New("--decrypt only applies to --from-file and --from-dir")
+	case from != "":
+		secrets, err = readSecretsVault(ctx, client, from, cmd.String("mount"), kvVersion)
+		if err != nil {
+			return nil, err
+		}
+		// the secrets were read from the source, so write them to the target
+		client, err = vaultclient.NewTargetClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize target vault client: %w", err)
+		}
+		if err := vaultclient.CheckSealed(ctx, client, "target"); err != nil {
+			return nil, err
+		}
+	case dir != "":
+		if cmd.String("mount") == "" {
+			return nil, errors.New("--mount flag is required with --from-dir")
+		}
+		secrets, err = readSecretsDir(ctx, dir, cmd.String("mount"), cmd.String("format"), cmd.String("decrypt"))
+	case filePath != "":
+		secrets, err = readSecretsFile(ctx, filePath, cmd.String("format"), cmd.String("decrypt"))
+	default:
+		return nil, errors.New("--from-file, --from-dir or --from-vault flag is required")
 	}
-
-	mountsMap, err := GetSecretEngines(ctx)
 	if err != nil {
-		slog.Error("unable to list KV secret engines", "error", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	for secretPath, secretData := range secrets {
-		mountInfo, relativePath, err := findMountForSecret(ctx, secretPath, mountsMap)
-		if err != nil {
-			slog.Error("mount not found for secret", "path", secretPath)
-			continue
+	// secrets read from Vault are copied byte for byte; a literal ${...} in one is data
+	if !cmd.Bool("no-interpolate") && from == "" {
+		if err := interpolateEnv(secrets, cmd.Bool("allow-unset")); err != nil {
+			return nil, err
 		}
+	}
 
-		mount := strings.TrimSuffix(mountInfo.MountPath, "/")
-		switch mountInfo.Version {
-		case "2":
-			req := schema.KvV2WriteRequest{
-				Data: secretData,
+	if keys := cmd.StringSlice("base64-encode-keys"); len(keys) > 0 {
+		for secretPath, data := range secrets {
+			if err := kv.EncodeBase64Fields(data, keys); err != nil {
+				return nil, fmt.Errorf("invalid secret %q: %w", secretPath, err)
 			}
-			resp, err := client.Secrets.KvV2Write(ctx, relativePath, req, vault.WithMountPath(mount))
-			if err != nil {
-				slog.Error("failed to write KV v2 secret", "path", secretPath, "error", err)
-				continue
-			}
-			slog.Info("KV v2 secret written", "path", secretPath, "version", resp.Data.Version)
-		case "1":
-			_, err := client.Secrets.KvV1Write(ctx, relativePath, secretData, vault.WithMountPath(mount))
-			if err != nil {
-				slog.Error("failed to write KV v1 secret", "path", secretPath, "error", err)
-				continue
+		}
+	}
+
+	if cmd.Bool("validate-only") {
+		if err := kv.ValidateSecrets(ctx, client, secrets); err != nil {
+			slog.Error("input is invalid", "error", err)
+			return nil, err
+		}
+		fmt.Println(color.Summary("validation finished", color.Count{Label: "valid secrets", N: len(secrets), Paint: color.Green}))
+		return nil, nil
+	}
+
+	return kv.CreateSecrets(ctx, client, secrets, kv.CreateOptions{
+		Strict:        cmd.Bool("strict"),
+		MaxSecretSize: cmd.Int("max-secret-size"),
+		RateLimit:     cmd.Float("rate-limit"),
+		KVVersion:     kvVersion,
+		Overwrite:     cmd.Bool("overwrite"),
+		OnlyChanged:   cmd.Bool("only-changed"),
+		WriteOptions:  options,
+	})
+}
+
+// readSecretsFile loads a file mapping full secret paths to their data, decrypting it
+// first as decrypt asks. A filePath of "-" reads stdin.
+func readSecretsFile(ctx context.Context, filePath, format, decrypt string) (map[string]map[string]interface{}, error) {
+	var raw []byte
+	var err error
+	if filePath == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file: %w", err)
+	}
+
+	if format, err = inputFormat(format, filePath, raw); err != nil {
+		return nil, err
+	}
+	if raw, err = decryptInput(ctx, decrypt, filePath, format, raw); err != nil {
+		return nil, err
+	}
+
+	var secrets map[string]map[string]interface{}
+	if err := decodeInput(format, filePath, raw, &secrets); err != nil {
+		return nil, err
+	}
+	if sops, ok := secrets["sops"]; ok && isSOPSMetadata(map[string]interface{}(sops)) {
+		return nil, errSOPSEncrypted
+	}
+	return secrets, nil
+}
+
+// readSecretsDir loads one secret per file under dir, as written by "export
+// --output-dir". Each file's path relative to dir, without its extension, becomes the
+// secret's path within mount, so out/app/db.json is written to <mount>/app/db. Only
+// .json, .yaml and .yml files are read, each decrypted first as decrypt asks, and hidden
+// files and directories such as .git are skipped.
+func readSecretsDir(ctx context.Context, dir, mount, format, decrypt string) (map[string]map[string]interface{}, error) {
+	secrets := make(map[string]map[string]interface{})
+
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && file != dir {
+			if entry.IsDir() {
+				return filepath.SkipDir
 			}
-			slog.Info("KV v1 secret written", "path", secretPath)
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		ext := filepath.Ext(file)
+		switch strings.ToLower(ext) {
+		case ".json", ".yaml", ".yml":
 		default:
-			slog.Error("unsupported KV version", "version", mountInfo.Version, "path", secretPath)
+			slog.Warn("skipping file without a JSON or YAML extension", "file", file)
+			return nil
+		}
+
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to load file: %w", err)
+		}
+		fileFormat, err := inputFormat(format, file, raw)
+		if err != nil {
+			return err
 		}
+		if raw, err = decryptInput(ctx, decrypt, file, fileFormat, raw); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		var data map[string]interface{}
+		if err := decodeInput(fileFormat, file, raw, &data); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if isSOPSMetadata(data["sops"]) {
+			return fmt.Errorf("%s: %w", file, errSOPSEncrypted)
+		}
+
+		rel, err := filepath.Rel(dir, strings.TrimSuffix(file, ext))
+		if err != nil {
+			return err
+		}
+		secrets[path.Join(kv.NormalizeMount(mount), filepath.ToSlash(rel))] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	return nil
+	return secrets, nil
 }
 
-// GetSecretEngines retrieves all enabled secret engine mounts from the Vault server
-// and returns a map of mount paths to their associated MountInfo.
-//
-// It inspects each mount's options to determine whether it is a KV v1 or v2 engine.
-// If the version is not explicitly set in the mount's options, it defaults to an
-// empty string, which should be treated as v1 by convention.
-//
-// This function is used to dynamically discover available KV mounts and their versions
-// for secret write operations.
-func GetSecretEngines(ctx context.Context) (map[string]MountInfo, error) {
-	client := vaultclient.GetVaultClient(ctx)
-	if client == nil {
-		return nil, errors.New("vault client not found in context")
+// readSecretsVault reads every secret under from, a path such as "secret/app" on client,
+// keyed by full secret path. Secrets keep their path within the mount; mount, when set,
+// replaces the mount they were read from. kvVersion, when set, overrides the source
+// mount's detected version, and from's first segment is then taken as its mount.
+func readSecretsVault(ctx context.Context, client *vault.Client, from, mount, kvVersion string) (map[string]map[string]interface{}, error) {
+	mountInfo, prefix, err := findMount(ctx, client, from, kvVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --from-vault: %w", err)
+	}
+	kvVersion = mountInfo.Version
+
+	sourceMount := kv.NormalizeMount(mountInfo.MountPath)
+	targetMount := sourceMount
+	if mount != "" {
+		targetMount = kv.NormalizeMount(mount)
 	}
 
-	resp, err := client.System.MountsListSecretsEngines(ctx)
+	secrets := make(map[string]map[string]interface{})
+	opts := kv.WalkOptions{Mount: mountInfo.MountPath, Prefix: prefix, KVVersion: kvVersion}
+	err = kv.ExportSecrets(ctx, client, opts, func(secretPath string, data map[string]interface{}) error {
+		relativePath := kv.RelativeSecretPath(sourceMount, secretPath)
+		secrets[path.Join(targetMount, relativePath)] = data
+		return nil
+	})
 	if err != nil {
-		slog.Error("Failed to list secret engines", "error", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to read secrets from source vault: %w", err)
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("no secrets found under %q on the source vault", from)
 	}
 
-	mounts := make(map[string]MountInfo)
-	for mountPath, raw := range resp.Data {
-		data, ok := raw.(map[string]interface{})
-		if !ok {
-			slog.Warn("unexpected mount data format", "mountPath", mountPath)
-			continue
-		}
+	slog.Info("read secrets from source vault", "path", from, "secrets", len(secrets))
+	return secrets, nil
+}
+
+// envPlaceholder matches a ${VAR} reference to an environment variable.
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
 
-		version := ""
-		if options, ok := data["options"].(map[string]interface{}); ok {
-			if v, ok := options["version"].(string); ok {
-				version = v
-				if version == "" {
-					slog.Warn("version not found")
+// interpolateEnv replaces ${VAR} placeholders in every string value of secrets, at any
+// nesting level, with the value of the environment variable VAR. Keys and paths are left
+// as they are. Unless allowUnset is set, a reference to an unset variable is an error
+// naming each secret and variable involved; otherwise it becomes an empty string.
+func interpolateEnv(secrets map[string]map[string]interface{}, allowUnset bool) error {
+	var unset []string
+
+	var expand func(secretPath string, v interface{}) interface{}
+	expand = func(secretPath string, v interface{}) interface{} {
+		switch val := v.(type) {
+		case string:
+			return envPlaceholder.ReplaceAllStringFunc(val, func(ref string) string {
+				name := envPlaceholder.FindStringSubmatch(ref)[1]
+				value, ok := os.LookupEnv(name)
+				if !ok && !allowUnset {
+					unset = append(unset, fmt.Sprintf("%s (in %s)", name, secretPath))
 				}
+				return value
+			})
+		case map[string]interface{}:
+			for key, nested := range val {
+				val[key] = expand(secretPath, nested)
+			}
+		case []interface{}:
+			for i, nested := range val {
+				val[i] = expand(secretPath, nested)
 			}
 		}
+		return v
+	}
 
-		mounts[mountPath] = MountInfo{
-			MountPath: mountPath,
-			Version:   version,
-		}
+	for secretPath, data := range secrets {
+		expand(secretPath, data)
 	}
 
-	return mounts, nil
+	if len(unset) > 0 {
+		sort.Strings(unset)
+		unset = slices.Compact(unset)
+		return fmt.Errorf("unset environment variables referenced: %s (set them, pass --allow-unset, or pass --no-interpolate)", strings.Join(unset, ", "))
+	}
+	return nil
 }
 
-// findMountForSecret determines the Vault mount that a secret path belongs to
-// and returns the corresponding MountInfo along with the path relative to the mount.
-//
-// It selects the longest matching mount prefix from the available mounts to ensure
-// the most specific match is chosen. This is important when mounts overlap, such as
-// "secrets/" and "secrets/internal/".
+// yamlQuotedValue matches a value quoted in a yaml.v3 error message.
+var yamlQuotedValue = regexp.MustCompile("`[^`]*`")
+
+// decodeInput unmarshals raw into v as JSON or YAML, as resolved by inputFormat.
 //
-// For example, given a secretPath of "secrets/users/user1" and a mount "secrets/",
-// it will return the MountInfo for "secrets/" and the relative path "users/user1".
-func findMountForSecret(ctx context.Context, secretPath string, mounts map[string]MountInfo) (MountInfo, string, error) {
-	client := vaultclient.GetVaultClient(ctx)
-	if client == nil {
-		return MountInfo{}, "", errors.New("vault client not found in context")
+// JSON numbers are decoded as json.Number, so they are written to Vault exactly as
+// given: integers beyond 2^53 keep every digit and "1.10" stays "1.10". YAML numbers
+// decode to Go ints and floats; see warnLossyNumbers.
+func decodeInput(format, filePath string, raw []byte, v interface{}) error {
+	format, err := inputFormat(format, filePath, raw)
+	if err != nil {
+		return err
 	}
 
-	var bestMatch string
-	for mount := range mounts {
-		if strings.HasPrefix(secretPath, mount) && len(mount) > len(bestMatch) {
-			bestMatch = mount
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(v); err != nil {
+			return jsonDecodeError(raw, err)
+		}
+		end := dec.InputOffset()
+		if _, err := dec.Token(); err != io.EOF {
+			trailing := end + int64(len(raw[end:])-len(bytes.TrimLeft(raw[end:], " \t\r\n")))
+			return jsonPositionError(raw, trailing+1, errors.New("unexpected data after the top-level value"))
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(raw, v); err != nil {
+			// yaml.v3 quotes the offending value in backticks, and values are secrets
+			return fmt.Errorf("invalid YAML structure: %s", yamlQuotedValue.ReplaceAllString(err.Error(), "<value>"))
 		}
 	}
+	return nil
+}
 
-	relativePath := strings.TrimPrefix(secretPath, bestMatch)
-	relativePath = strings.TrimSuffix(relativePath, "/")
+// jsonDecodeError describes err, returned while decoding raw as JSON, with the line and
+// column where decoding failed, so hand-edited files can be fixed without counting bytes.
+// The offending input itself is never quoted, since it usually holds secret values that
+// were not registered for redaction. Errors without a position are returned as "invalid
+// JSON structure" errors.
+func jsonDecodeError(raw []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		offset = int64(len(raw))
+		err = errors.New("unexpected end of input; check for a missing closing brace, bracket or quote")
+	default:
+		return fmt.Errorf("invalid JSON structure: %w", err)
+	}
+	return jsonPositionError(raw, offset, err)
+}
 
-	return mounts[bestMatch], relativePath, nil
+// jsonPositionError wraps err with the position in raw of the byte before offset, the
+// number of bytes read when decoding failed.
+func jsonPositionError(raw []byte, offset int64, err error) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("invalid JSON structure: %w", err)
+	}
+	offset = min(max(offset, 1), int64(len(raw)))
+	before := raw[:offset-1]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	column := utf8.RuneCount(raw[lineStart:offset-1]) + 1
+	return fmt.Errorf("invalid JSON at line %d, column %d: %w", line, column, err)
+}
+
+// inputFormat resolves --format to "json" or "yaml". In auto mode the file extension
+// decides; files without a recognized extension, including stdin, are sniffed: a
+// document starting with '{' is JSON, anything else is YAML.
+func inputFormat(format, filePath string, raw []byte) (string, error) {
+	switch format {
+	case "json", "yaml":
+		return format, nil
+	case "auto", "":
+	default:
+		return "", fmt.Errorf("--format must be json, yaml or auto, got %q", format)
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return "json", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	}
+
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		return "json", nil
+	}
+	return "yaml", nil
 }
@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		filePath string
+		raw      string
+		want     map[string]map[string]interface{}
+	}{
+		{
+			name:   "json nested objects",
+			format: "json",
+			raw:    `{"secret/app": {"db": {"primary": {"host": "db1", "port": 5432}}}}`,
+			want: map[string]map[string]interface{}{
+				"secret/app": {"db": map[string]interface{}{"primary": map[string]interface{}{"host": "db1", "port": json.Number("5432")}}},
+			},
+		},
+		{
+			name:   "json arrays",
+			format: "json",
+			raw:    `{"secret/app": {"hosts": ["a", "b"], "matrix": [[1, 2], []], "mixed": [true, "x", {"k": "v"}]}}`,
+			want: map[string]map[string]interface{}{
+				"secret/app": {
+					"hosts":  []interface{}{"a", "b"},
+					"matrix": []interface{}{[]interface{}{json.Number("1"), json.Number("2")}, []interface{}{}},
+					"mixed":  []interface{}{true, "x", map[string]interface{}{"k": "v"}},
+				},
+			},
+		},
+		{
+			name:   "json nulls",
+			format: "json",
+			raw:    `{"secret/app": {"token": null, "nested": {"empty": null}, "list": [null]}}`,
+			want: map[string]map[string]interface{}{
+				"secret/app": {"token": nil, "nested": map[string]interface{}{"empty": nil}, "list": []interface{}{nil}},
+			},
+		},
+		{
+			name:   "json unicode",
+			format: "json",
+			raw:    `{"secret/ünï": {"greeting": "héllo wörld", "escaped": "caf\u00e9", "emoji": "🔑", "cjk": "秘密"}}`,
+			want: map[string]map[string]interface{}{
+				"secret/ünï": {"greeting": "héllo wörld", "escaped": "café", "emoji": "🔑", "cjk": "秘密"},
+			},
+		},
+		{
+			name:   "json numbers keep every digit",
+			format: "json",
+			raw:    `{"secret/app": {"big": 12345678901234567890, "ratio": 1.10, "exp": 1e400, "neg": -0}}`,
+			want: map[string]map[string]interface{}{
+				"secret/app": {"big": json.Number("12345678901234567890"), "ratio": json.Number("1.10"), "exp": json.Number("1e400"), "neg": json.Number("-0")},
+			},
+		},
+		{
+			name:     "yaml nested objects, arrays, nulls and unicode",
+			format:   "yaml",
+			filePath: "secrets.yaml",
+			raw: `secret/app:
+  db:
+    primary:
+      host: db1
+  hosts: [a, b]
+  token: null
+  tilde: ~
+  greeting: héllo 🔑
+`,
+			want: map[string]map[string]interface{}{
+				"secret/app": {
+					"db":       map[string]interface{}{"primary": map[string]interface{}{"host": "db1"}},
+					"hosts":    []interface{}{"a", "b"},
+					"token":    nil,
+					"tilde":    nil,
+					"greeting": "héllo 🔑",
+				},
+			},
+		},
+		{
+			name:     "format detected from the extension",
+			format:   "auto",
+			filePath: "secrets.json",
+			raw:      `{"secret/app": {"port": 8080}}`,
+			want:     map[string]map[string]interface{}{"secret/app": {"port": json.Number("8080")}},
+		},
+		{
+			name:     "json detected from the content",
+			format:   "auto",
+			filePath: "-",
+			raw:      "\n  {\"secret/app\": {\"port\": 8080}}",
+			want:     map[string]map[string]interface{}{"secret/app": {"port": json.Number("8080")}},
+		},
+		{
+			name:     "yaml detected from the content",
+			format:   "auto",
+			filePath: "-",
+			raw:      "secret/app:\n  user: app\n",
+			want:     map[string]map[string]interface{}{"secret/app": {"user": "app"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]map[string]interface{}
+			if err := decodeInput(tt.format, tt.filePath, []byte(tt.raw), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decodeInput() = %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInputErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		raw    string
+		want   string
+	}{
+		{
+			name:   "json syntax error",
+			format: "json",
+			raw:    "{\n  \"secret/app\": {\n    \"password\": \"hunter2\",}\n}",
+			want:   "invalid JSON at line 3, column 27",
+		},
+		{
+			name:   "json unexpected end",
+			format: "json",
+			raw:    `{"secret/app": {"password": "hunter2"`,
+			want:   "unexpected end of input",
+		},
+		{
+			name:   "json trailing data",
+			format: "json",
+			raw:    `{"secret/app": {"password": "hunter2"}} {"secret/other": {}}`,
+			want:   "unexpected data after the top-level value",
+		},
+		{
+			name:   "json wrong type",
+			format: "json",
+			raw:    `{"secret/app": "hunter2"}`,
+			want:   "invalid JSON at line 1",
+		},
+		{
+			name:   "yaml wrong type",
+			format: "yaml",
+			raw:    "secret/app: hunter2\n",
+			want:   "invalid YAML structure",
+		},
+		{
+			name:   "unknown format",
+			format: "toml",
+			raw:    `password = "hunter2"`,
+			want:   "--format must be json, yaml or auto",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]map[string]interface{}
+			err := decodeInput(tt.format, "-", []byte(tt.raw), &got)
+			if err == nil {
+				t.Fatal("decodeInput() succeeded")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("error %q quotes a secret value", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Package secrets implements the "delete" subcommand under the "secrets" command in the vaultx CLI.
+
+The "delete" command deletes a single secret by default. Deleting every secret under a path
+requires an explicit --recursive and a confirmation, so a mistyped path can't wipe a subtree.
+
+On KV v2 the latest version of each secret is soft-deleted by default and can be undeleted.
+--destroy removes every version and the secret's metadata instead, permanently, and always asks
+for confirmation. KV v1 keeps no versions, so its secrets are always removed permanently and
+even a single delete asks for confirmation; --destroy is rejected there, since it would change
+nothing. Log lines, prompts and the summary name which of these happened.
+
+Usage:
+  vaultx secrets delete --path=<mount/path>
+  vaultx secrets delete --path=<mount/path> --destroy [--yes]
+  vaultx secrets delete --path=<mount/path/> --recursive [--destroy] [--yes]
+
+Flags:
+  --path        Full secret path, including the mount (e.g. secret/app/db).
+  --recursive   Delete every secret under --path instead of a single secret.
+  --destroy     On KV v2, permanently remove every version and the metadata instead of soft-deleting.
+  --yes         Skip the confirmation prompt for --recursive, --destroy and KV v1 deletes.
+  --kv-version  Force KV v1 or v2 behavior instead of detecting it from the mount.
+
+Without --recursive a path ending in / is rejected, since it names a directory, not a secret.
+*/
+
+package secrets
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func DeleteCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "Delete a secret, or every secret under a path with --recursive",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "path",
+			},
+			&cli.BoolFlag{
+				Name:  "recursive",
+				Usage: "Delete every secret under --path",
+			},
+			&cli.BoolFlag{
+				Name:  "destroy",
+				Usage: "On KV v2, permanently remove every version and the metadata instead of soft-deleting the latest version",
+			},
+			&cli.BoolFlag{
+				Name:  "yes",
+				Usage: "Do not ask for confirmation before a recursive delete, --destroy or a permanent KV v1 delete",
+			},
+			kvVersionFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return DeleteSecrets(ctx, cmd)
+		},
+	}
+}
+
+// DeleteSecrets deletes the secret at --path or, with --recursive and after
+// confirmation, every secret under it. KV v2 secrets are soft-deleted unless --destroy
+// is passed; permanent deletes, with --destroy or on KV v1, ask for confirmation even
+// for a single secret.
+func DeleteSecrets(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	secretPath := cmd.String("path")
+	if secretPath == "" {
+		slog.Error("--path flag is required")
+		os.Exit(1)
+	}
+	recursive := cmd.Bool("recursive")
+	if !recursive && strings.HasSuffix(secretPath, "/") {
+		slog.Error("--path names a directory; pass --recursive to delete every secret under it", "path", secretPath)
+		os.Exit(1)
+	}
+
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	mountInfo, relativePath, err := findMount(ctx, client, secretPath, kvVersion)
+	if err != nil {
+		slog.Error("mount not found for secret", "path", secretPath, "error", err)
+		return err
+	}
+	if cmd.Bool("destroy") && mountInfo.Version != "2" {
+		return fmt.Errorf("--destroy requires a KV v2 mount, %q is KV v%s and its deletes are always permanent", mountInfo.MountPath, mountInfo.Version)
+	}
+	mode := newDeleteMode(mountInfo.Version, cmd.Bool("destroy"))
+
+	if !recursive {
+		if relativePath == "" {
+			slog.Error("--path names a mount, not a secret", "path", secretPath)
+			os.Exit(1)
+		}
+		if mode.permanent && !cmd.Bool("yes") {
+			ok, err := confirm(fmt.Sprintf(mode.question, "the secret at "+secretPath))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				slog.Info("delete aborted")
+				return nil
+			}
+		}
+		if err := mode.delete(ctx, client, mountInfo, relativePath); err != nil {
+			slog.Error("failed to delete secret", "path", secretPath, "error", err)
+			return err
+		}
+		slog.Info(mode.logMessage, "path", secretPath)
+		return nil
+	}
+
+	secretsList, err := kv.ListSecrets(ctx, client, kv.WalkOptions{
+		Mount:     mountInfo.MountPath,
+		Prefix:    relativePath,
+		KVVersion: mountInfo.Version,
+	})
+	if err != nil {
+		slog.Error("failed to list secrets", "path", secretPath, "error", err)
+		return err
+	}
+	if len(secretsList) == 0 {
+		slog.Info("no secrets found", "path", secretPath)
+		return nil
+	}
+
+	if !cmd.Bool("yes") {
+		ok, err := confirm(fmt.Sprintf(mode.question, fmt.Sprintf("%d secrets under %s", len(secretsList), secretPath)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			slog.Info("delete aborted")
+			return nil
+		}
+	}
+
+	var deleted, failed int
+	for _, fullPath := range secretsList {
+		rel := kv.RelativeSecretPath(mountInfo.MountPath, fullPath)
+		if err := mode.delete(ctx, client, mountInfo, rel); err != nil {
+			slog.Error("failed to delete secret", "path", fullPath, "error", err)
+			failed++
+			continue
+		}
+		slog.Info(mode.logMessage, "path", fullPath)
+		deleted++
+	}
+
+	fmt.Println(color.Summary("delete finished",
+		color.Count{Label: mode.label, N: deleted, Paint: color.Green},
+		color.Count{Label: "failed", N: failed, Paint: color.Red},
+	))
+	if failed > 0 {
+		return errors.New("some secrets could not be deleted")
+	}
+	return nil
+}
+
+// deleteMode describes what deleting does to a secret on one mount, so prompts and output
+// say whether it can be undone.
+type deleteMode struct {
+	destroy    bool
+	permanent  bool   // the secret cannot be recovered afterwards
+	label      string // past tense for the summary, e.g. "soft-deleted"
+	logMessage string // logged for each secret
+	question   string // confirmation prompt; %s names what is deleted
+}
+
+// newDeleteMode returns the mode for a mount of the given KV version. destroy only makes
+// a difference on KV v2; KV v1 deletes are always permanent.
+func newDeleteMode(version string, destroy bool) deleteMode {
+	switch {
+	case version != "2":
+		return deleteMode{
+			permanent:  true,
+			label:      "deleted permanently",
+			logMessage: "secret deleted permanently (KV v1 keeps no versions)",
+			question:   "Permanently delete %s? KV v1 keeps no versions, so this cannot be undone.",
+		}
+	case destroy:
+		return deleteMode{
+			destroy:    true,
+			permanent:  true,
+			label:      "destroyed",
+			logMessage: "secret destroyed with all versions and metadata",
+			question:   "Permanently destroy %s, with every version and the metadata? This cannot be undone.",
+		}
+	default:
+		return deleteMode{
+			label:      "soft-deleted",
+			logMessage: "secret soft-deleted; undelete the latest version to recover it",
+			question:   "Soft-delete the latest version of %s? Soft-deleted versions can be undeleted.",
+		}
+	}
+}
+
+// delete removes the secret at relativePath as the mode describes.
+func (m deleteMode) delete(ctx context.Context, client *vault.Client, mountInfo kv.MountInfo, relativePath string) error {
+	if m.destroy {
+		return kv.DestroySecret(ctx, client, nil, mountInfo, relativePath)
+	}
+	return kv.DeleteSecret(ctx, client, nil, mountInfo, relativePath)
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin. Anything but
+// "y" or "yes" is a no.
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("no confirmation received (pass --yes to skip it): %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,29 @@
+package secrets
+
+import (
+	"log/slog"
+
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// dedupeFlag returns the --dedupe flag shared by export and copy.
+func dedupeFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "dedupe",
+		Usage: "Report groups of secrets that hold identical data",
+	}
+}
+
+// reportDuplicates logs each group of secrets in index holding identical data, and how
+// many secrets could be dropped by keeping one of each group. The report goes to the log
+// rather than stdout, which may be carrying an export.
+func reportDuplicates(index *kv.DuplicateIndex) {
+	groups := index.Groups()
+	redundant := 0
+	for _, group := range groups {
+		slog.Warn("secrets hold identical data", "paths", group)
+		redundant += len(group) - 1
+	}
+	slog.Info("duplicate report", "groups", len(groups), "redundant_secrets", redundant)
+}
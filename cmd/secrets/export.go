@@ -0,0 +1,294 @@
+/*
+Package secrets implements the "export" subcommand under the "secrets" command in the vaultx CLI.
+
+The "export" command reads every secret under a KV mount and writes it out as JSON. By default
+all secrets are written as a single JSON object keyed by full secret path, the same format
+"create --from-file" accepts, so an export can be re-imported as is. With --output-dir each
+secret is written to its own file instead, mirroring its path within the mount on disk (e.g.
+out/app/db.json), which is friendlier for tracking individual secrets in git. With --jsonl
+each secret is written as soon as it is read, as one {"path": ..., "data": ...} JSON object
+per line, so large mounts can be streamed into tools like jq without holding the whole
+export in memory.
+
+Usage:
+  vaultx secrets export --mount=<mount-path> [--prefix=<sub-path>] [--out=<file> | --output-dir=<dir>]
+  vaultx secrets export --mount=<mount-path> --jsonl [--out=<file>]
+
+Flags:
+  --mount, --source-mount   The KV mount to export.
+  --prefix                  Only export secrets under this path within the mount.
+  --max-depth               Maximum number of path levels to descend (0 for unlimited).
+  --out                     Write the export to this file instead of stdout.
+  --output-dir              Write each secret to its own file under this directory.
+  --jsonl                   Write one JSON object per secret and line instead of a single object.
+  --kv-version              Force KV v1 or v2 behavior instead of detecting it from the mount.
+  --base64-decode-keys      Base64-decode these fields, which must hold encoded text such as PEM, in every secret.
+  --dedupe                  Report groups of secrets holding identical data.
+  --with-timestamps         Record each KV v2 secret's version, created_time and updated_time in its _meta block.
+
+Exported files contain secret values in plain text and are created readable by the current
+user only. Each file is written to a temporary file and renamed into place, so a failed export
+never leaves a truncated file behind. Secrets whose path cannot be safely mapped to a file under --output-dir (e.g. a
+".." segment) are skipped with an error.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func ExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export the secrets under a mount as JSON",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "source-mount",
+				Aliases: []string{"mount"},
+			},
+			&cli.StringFlag{
+				Name:  "prefix",
+				Usage: "Only export secrets under this path within the mount",
+			},
+			&cli.IntFlag{
+				Name:  "max-depth",
+				Usage: "Maximum number of path levels to descend below the traversal start (0 for unlimited)",
+			},
+			outFlag(),
+			&cli.StringFlag{
+				Name:  "output-dir",
+				Usage: "Write each secret to its own JSON file under this directory",
+			},
+			&cli.BoolFlag{
+				Name:  "jsonl",
+				Usage: "Write one {\"path\": ..., \"data\": ...} JSON object per line as each secret is read",
+			},
+			kvVersionFlag(),
+			&cli.StringSliceFlag{
+				Name:  "base64-decode-keys",
+				Usage: "Base64-decode the values of these fields in every secret, which must decode to text such as PEM (repeatable or comma-separated)",
+			},
+			dedupeFlag(),
+			&cli.BoolFlag{
+				Name:  "with-timestamps",
+				Usage: "Record each KV v2 secret's version, created_time and updated_time in a _meta block",
+			},
+		},
+		ShellComplete: completeMounts,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if defaultMount := config.FromContext(ctx).DefaultMount; cmd.String("source-mount") == "" && defaultMount != "" {
+				if err := cmd.Set("source-mount", defaultMount); err != nil {
+					return err
+				}
+			}
+			if cmd.String("source-mount") == "" {
+				slog.Error("--mount flag is required")
+				os.Exit(1)
+			}
+			if cmd.String("out") != "" && cmd.String("output-dir") != "" {
+				slog.Error("--out and --output-dir cannot be used together")
+				os.Exit(1)
+			}
+			if cmd.Bool("jsonl") && cmd.String("output-dir") != "" {
+				slog.Error("--jsonl and --output-dir cannot be used together")
+				os.Exit(1)
+			}
+
+			return ExportSecrets(ctx, cmd)
+		},
+	}
+}
+
+// ExportSecrets writes the secrets under --mount to stdout, --out or --output-dir, as a
+// single JSON object or, with --jsonl, one object per line.
+func ExportSecrets(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	mount := cmd.String("source-mount")
+	opts := kv.WalkOptions{
+		Mount:     mount,
+		Prefix:    cmd.String("prefix"),
+		MaxDepth:  cmd.Int("max-depth"),
+		KVVersion: kvVersion,
+	}
+
+	// decode each secret before it reaches the writers below, so the export can be
+	// re-imported with create --base64-encode-keys
+	decodeKeys := cmd.StringSlice("base64-decode-keys")
+	var duplicates *kv.DuplicateIndex
+	if cmd.Bool("dedupe") {
+		duplicates = &kv.DuplicateIndex{}
+		defer func() { reportDuplicates(duplicates) }()
+	}
+	// timestamps come from each secret's metadata, which only KV v2 keeps
+	withTimestamps := cmd.Bool("with-timestamps")
+	if withTimestamps {
+		version := kvVersion
+		if version == "" {
+			mountInfo, err := kv.LookupKVMount(ctx, client, mount)
+			if err != nil {
+				return err
+			}
+			version = mountInfo.Version
+		}
+		if version != "2" {
+			slog.Warn("--with-timestamps requires KV v2 metadata, ignoring it", "version", version)
+			withTimestamps = false
+		}
+	}
+
+	export := func(fn func(secretPath string, data map[string]interface{}) error) error {
+		return kv.ExportSecrets(ctx, client, opts, func(secretPath string, data map[string]interface{}) error {
+			if err := kv.DecodeBase64TextFields(data, decodeKeys); err != nil {
+				return fmt.Errorf("%s: %w", secretPath, err)
+			}
+			if duplicates != nil {
+				if err := duplicates.Add(secretPath, data); err != nil {
+					return fmt.Errorf("%s: %w", secretPath, err)
+				}
+			}
+			if withTimestamps {
+				relativePath := kv.RelativeSecretPath(mount, secretPath)
+				origin, err := kv.ReadSecretOrigin(ctx, client, nil, mount, relativePath)
+				if err != nil {
+					return fmt.Errorf("%s: failed to read metadata: %w", secretPath, err)
+				}
+				data[kv.MetaKey] = origin
+			}
+			return fn(secretPath, data)
+		})
+	}
+
+	if dir := cmd.String("output-dir"); dir != "" {
+		var written, skipped int
+		err := export(func(secretPath string, data map[string]interface{}) error {
+			file, err := secretFilePath(dir, mount, secretPath)
+			if err != nil {
+				slog.Error("skipping secret", "path", secretPath, "error", err)
+				skipped++
+				return nil
+			}
+			if err := writeJSONFile(file, data); err != nil {
+				return err
+			}
+			written++
+			return nil
+		})
+		if err != nil {
+			slog.Error("failed to export secrets", "error", err)
+			return err
+		}
+
+		fmt.Println(color.Summary("export to "+dir+" finished",
+			color.Count{Label: "written", N: written, Paint: color.Green},
+			color.Count{Label: "skipped", N: skipped, Paint: color.Yellow},
+		))
+		if skipped > 0 {
+			return errors.New("some secrets could not be exported")
+		}
+		return nil
+	}
+
+	if cmd.Bool("jsonl") {
+		out, err := openOutput(cmd.String("out"), 0o600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		encoder := json.NewEncoder(out)
+		err = export(func(secretPath string, data map[string]interface{}) error {
+			return encoder.Encode(map[string]interface{}{
+				"path": secretPath,
+				"data": data,
+			})
+		})
+		if err != nil {
+			slog.Error("failed to export secrets", "error", err)
+			return err
+		}
+		return out.Commit()
+	}
+
+	secrets := make(map[string]map[string]interface{})
+	err = export(func(secretPath string, data map[string]interface{}) error {
+		secrets[secretPath] = data
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to export secrets", "error", err)
+		return err
+	}
+
+	if file := cmd.String("out"); file != "" {
+		if err := writeJSONFile(file, secrets); err != nil {
+			return err
+		}
+		slog.Info("secrets exported", "file", file, "secrets", len(secrets))
+		return nil
+	}
+
+	out, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(out, '\n'))
+	return err
+}
+
+// secretFilePath maps secretPath to a .json file under dir, mirroring its path within
+// mount. Key names come from Vault and are untrusted, so any path that could resolve
+// outside dir, or that is ambiguous on disk, is rejected.
+func secretFilePath(dir, mount, secretPath string) (string, error) {
+	rel := kv.RelativeSecretPath(mount, secretPath)
+
+	for _, segment := range strings.Split(rel, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "\\\x00") {
+			return "", fmt.Errorf("unsafe path segment %q", segment)
+		}
+	}
+
+	rel = filepath.FromSlash(rel) + ".json"
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("path %q escapes the output directory", rel)
+	}
+
+	return filepath.Join(dir, rel), nil
+}
+
+// writeJSONFile writes v as indented JSON to file, creating parent directories as
+// needed. Files and directories are only accessible by the current user, since they
+// hold secret values. The file is replaced atomically, so a failed write leaves any
+// previous file intact.
+func writeJSONFile(file string, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(file, append(out, '\n'), 0o600)
+}
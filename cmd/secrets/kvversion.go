@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault-client-go"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// kvVersionFlag returns the --kv-version flag shared by the commands that read or write
+// KV secrets.
+func kvVersionFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "kv-version",
+		Aliases: []string{"engine-version"},
+		Usage:   "Force KV v1 or v2 behavior (1 or 2) instead of detecting it from the mount; the path's first segment is then taken as the mount",
+	}
+}
+
+// kvVersionOverride returns the validated --kv-version value, or "" when the version
+// should be detected.
+func kvVersionOverride(cmd *cli.Command) (string, error) {
+	return versionFlag(cmd, "kv-version")
+}
+
+// targetKVVersionOverride returns the validated --target-kv-version value, or "" when
+// the target version should follow --kv-version or be detected.
+func targetKVVersionOverride(cmd *cli.Command) (string, error) {
+	return versionFlag(cmd, "target-kv-version")
+}
+
+// findMount returns the mount secretPath lives on and the path relative to it. With a
+// forced kvVersion the first path segment is taken as the mount without reading
+// sys/mounts, so the command works for tokens that may not list mounts.
+func findMount(ctx context.Context, client *vault.Client, secretPath, kvVersion string) (kv.MountInfo, string, error) {
+	if kvVersion != "" {
+		return kv.AssumeMount(secretPath, kvVersion)
+	}
+	mounts, err := kv.GetSecretEngines(ctx, client)
+	if err != nil {
+		return kv.MountInfo{}, "", fmt.Errorf("unable to list KV secret engines: %w", err)
+	}
+	return kv.FindMountForSecret(secretPath, mounts)
+}
+
+func versionFlag(cmd *cli.Command, name string) (string, error) {
+	switch v := cmd.String(name); v {
+	case "", "1", "2":
+		return v, nil
+	default:
+		return "", fmt.Errorf("--%s must be 1 or 2, got %q", name, v)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Package secrets implements the "lease" subcommand under the "secrets" command in the vaultx CLI.
+
+The "lease" command lets operators manage the leases attached to dynamic secrets (database
+credentials, cloud credentials, etc.) without reaching for the full vault CLI. It calls the
+sys/leases endpoints to extend or revoke a lease by its ID.
+
+Usage:
+  vaultx secrets lease renew --lease-id=<lease-id> [--increment=<duration>]
+  vaultx secrets lease revoke --lease-id=<lease-id>
+
+Flags:
+  --lease-id    The ID of the lease to act on.
+  --increment   Requested lease extension for renew (e.g. "1h" or "3600").
+
+This subcommand is intended for operators managing short-lived dynamic credentials.
+*/
+
+package secrets
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/urfave/cli/v3"
+)
+
+func LeaseCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lease",
+		Usage: "Renew or revoke leases on dynamic secrets",
+		Commands: []*cli.Command{
+			{
+				Name:  "renew",
+				Usage: "Renew a lease by ID",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "lease-id",
+					},
+					&cli.StringFlag{
+						Name:  "increment",
+						Usage: "Requested lease extension (e.g. 1h or 3600)",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return RenewLease(ctx, cmd)
+				},
+			},
+			{
+				Name:  "revoke",
+				Usage: "Revoke a lease by ID",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "lease-id",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return RevokeLease(ctx, cmd)
+				},
+			},
+		},
+	}
+}
+
+// RenewLease extends the lease identified by --lease-id via sys/leases/renew.
+//
+// If --increment is set it is passed through as the requested extension; Vault may
+// grant less than requested depending on the backing role's max TTL.
+func RenewLease(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	leaseID := cmd.String("lease-id")
+	if leaseID == "" {
+		slog.Error("--lease-id flag is required")
+		os.Exit(1)
+	}
+
+	req := schema.LeasesRenewLeaseRequest{
+		LeaseId:   leaseID,
+		Increment: cmd.String("increment"),
+	}
+	resp, err := client.System.LeasesRenewLease(ctx, req)
+	if err != nil {
+		slog.Error("failed to renew lease", "lease_id", leaseID, "error", err)
+		return err
+	}
+
+	slog.Info("lease renewed", "lease_id", resp.LeaseID, "lease_duration", resp.LeaseDuration, "renewable", resp.Renewable)
+	return nil
+}
+
+// RevokeLease immediately revokes the lease identified by --lease-id via sys/leases/revoke.
+func RevokeLease(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	leaseID := cmd.String("lease-id")
+	if leaseID == "" {
+		slog.Error("--lease-id flag is required")
+		os.Exit(1)
+	}
+
+	req := schema.LeasesRevokeLeaseRequest{
+		LeaseId: leaseID,
+	}
+	if _, err := client.System.LeasesRevokeLease(ctx, req); err != nil {
+		slog.Error("failed to revoke lease", "lease_id", leaseID, "error", err)
+		return err
+	}
+
+	slog.Info("lease revoked", "lease_id", leaseID)
+	return nil
+}
@@ -0,0 +1,299 @@
+/*
+Package secrets implements the "list" subcommand under the "secrets" command in the vaultx CLI.
+
+The "list" command recursively traverses a KV mount and prints the full path of every secret
+it finds, one per line, as soon as it is discovered. With --jsonl each line is a JSON object
+instead, suitable for streaming into tools like jq. Only paths are printed; secret values are never read, so the output
+is always safe to paste into logs or tickets.
+
+With --only-keys every secret is read instead, and the distinct field names used across them
+are printed with how many secrets use each, to audit schema consistency. Values are still
+never printed.
+
+Usage:
+  vaultx secrets list --mount=<mount-path> [--prefix=<sub-path>] [--max-depth=<n>] [--jsonl]
+  vaultx secrets list --mount=<kv-v2-mount> --show-versions [--threads=<n>]
+  vaultx secrets list --mount=<mount-path> --only-keys [--jsonl]
+  vaultx secrets list --mount=<mount-path> --out=<file>
+
+Flags:
+  --mount, --source-mount   The KV mount to traverse.
+  --prefix                  Only traverse secrets under this path within the mount.
+  --max-depth               Maximum number of path levels to descend (0 for unlimited).
+  --max-secrets             Abort once more than this many secrets are found (0 for unlimited).
+  --jsonl                   Emit one JSON object per line.
+  --kv-version              Force KV v1 or v2 behavior instead of detecting it from the mount.
+  --show-versions           Also show each KV v2 secret's current version and whether it is deleted or destroyed.
+  --threads                 Number of metadata reads to run concurrently with --show-versions.
+  --only-keys               Print each field name used across the secrets and how many secrets use it.
+  --out                     Write the output to this file instead of stdout.
+
+With --show-versions the whole mount is listed first and each secret's metadata is then read,
+several at a time, before printing in traversal order; metadata holds no secret values.
+
+With --out paths are still written as they are discovered, but to a temporary file that only
+replaces the target file once the whole mount was listed, so a failed run, including one
+stopped by --max-secrets, leaves the previous listing intact.
+
+This subcommand is useful for surveying a mount before copying it.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/razahuss02/vaultx/internal/config"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List secret paths under a mount",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "source-mount",
+				Aliases: []string{"mount"},
+			},
+			&cli.StringFlag{
+				Name:  "prefix",
+				Usage: "Only traverse secrets under this path within the mount",
+			},
+			&cli.BoolFlag{
+				Name:  "jsonl",
+				Usage: "Emit one JSON object per line as each secret is discovered",
+			},
+			&cli.IntFlag{
+				Name:  "max-depth",
+				Usage: "Maximum number of path levels to descend below the traversal start (0 for unlimited)",
+			},
+			&cli.IntFlag{
+				Name:  "max-secrets",
+				Usage: "Abort once more than this many secrets are found, as a guard against targeting the wrong mount (0 for unlimited)",
+			},
+			kvVersionFlag(),
+			&cli.BoolFlag{
+				Name:  "show-versions",
+				Usage: "Show each KV v2 secret's current version and deletion state",
+			},
+			&cli.IntFlag{
+				Name:  "threads",
+				Value: 8,
+				Usage: "Number of metadata reads to run concurrently with --show-versions",
+			},
+			&cli.BoolFlag{
+				Name:  "only-keys",
+				Usage: "Read every secret and print the field names used across them, with how many secrets use each",
+			},
+			outFlag(),
+		},
+		ShellComplete: completeMounts,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if defaultMount := config.FromContext(ctx).DefaultMount; cmd.String("source-mount") == "" && defaultMount != "" {
+				if err := cmd.Set("source-mount", defaultMount); err != nil {
+					return err
+				}
+			}
+			if cmd.String("source-mount") == "" {
+				slog.Error("--mount flag is required")
+				os.Exit(1)
+			}
+
+			client := vaultclient.GetVaultClient(ctx)
+			if client == nil {
+				return vaultclient.ErrVaultClientMissing
+			}
+
+			kvVersion, err := kvVersionOverride(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := kv.WalkOptions{
+				Mount:      cmd.String("source-mount"),
+				Prefix:     cmd.String("prefix"),
+				MaxDepth:   cmd.Int("max-depth"),
+				MaxSecrets: cmd.Int("max-secrets"),
+				KVVersion:  kvVersion,
+			}
+
+			if cmd.Bool("show-versions") && cmd.Bool("only-keys") {
+				return errors.New("--show-versions and --only-keys cannot be used together")
+			}
+
+			out, err := openOutput(cmd.String("out"), 0o600)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			switch {
+			case cmd.Bool("show-versions"):
+				err = listVersions(ctx, cmd, opts, out)
+			case cmd.Bool("only-keys"):
+				err = listFieldNames(ctx, cmd, opts, out)
+			default:
+				encoder := json.NewEncoder(out)
+				err = kv.WalkSecrets(ctx, client, opts, func(secretPath string) error {
+					if cmd.Bool("jsonl") {
+						return encoder.Encode(map[string]string{
+							"mount": strings.Trim(cmd.String("source-mount"), "/"),
+							"path":  secretPath,
+						})
+					}
+					_, err := fmt.Fprintln(out, secretPath)
+					return err
+				})
+			}
+			if errors.Is(err, kv.ErrSecretLimit) {
+				return fmt.Errorf("%w; pass a higher --max-secrets if the mount is expected to be this large", err)
+			}
+			if err != nil {
+				slog.Error("failed to list secrets", "error", err)
+				return err
+			}
+			return out.Commit()
+		},
+	}
+}
+
+// listVersions lists the secrets selected by opts with the current version and deletion
+// state of each, reading metadata for up to --threads secrets at a time. Output keeps
+// the order secrets were discovered in.
+func listVersions(ctx context.Context, cmd *cli.Command, opts kv.WalkOptions, out io.Writer) error {
+	client := vaultclient.GetVaultClient(ctx)
+
+	if opts.KVVersion == "" {
+		mountInfo, err := kv.LookupKVMount(ctx, client, opts.Mount)
+		if err != nil {
+			return err
+		}
+		opts.KVVersion = mountInfo.Version
+	}
+	if opts.KVVersion != "2" {
+		return fmt.Errorf("--show-versions requires a KV v2 mount, %q is KV v%s", opts.Mount, opts.KVVersion)
+	}
+	threads := cmd.Int("threads")
+	if threads < 1 {
+		return fmt.Errorf("--threads must be at least 1 (got %d)", threads)
+	}
+
+	secretsList, err := kv.ListSecrets(ctx, client, opts)
+	if err != nil {
+		slog.Error("failed to list secrets", "error", err)
+		return err
+	}
+
+	// each worker writes only its own secrets' entries, so states needs no lock
+	mount := kv.NormalizeMount(opts.Mount)
+	states := make([]kv.VersionState, len(secretsList))
+	errs := make([]error, len(secretsList))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				relativePath := kv.RelativeSecretPath(mount, secretsList[i])
+				states[i], errs[i] = kv.ReadVersionState(ctx, client, nil, mount, relativePath)
+			}
+		}()
+	}
+	for i := range secretsList {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	encoder := json.NewEncoder(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	failed := 0
+	for i, secretPath := range secretsList {
+		if errs[i] != nil {
+			slog.Error("failed to read secret metadata", "path", secretPath, "error", errs[i])
+			failed++
+			continue
+		}
+		if cmd.Bool("jsonl") {
+			if err := encoder.Encode(map[string]interface{}{
+				"mount":     mount,
+				"path":      secretPath,
+				"version":   states[i].Version,
+				"deleted":   states[i].Deleted,
+				"destroyed": states[i].Destroyed,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		state := "active"
+		switch {
+		case states[i].Destroyed:
+			state = "destroyed"
+		case states[i].Deleted:
+			state = "deleted"
+		}
+		fmt.Fprintf(w, "%s\tv%d\t%s\n", secretPath, states[i].Version, state)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to read metadata for %d secrets", failed)
+	}
+	return nil
+}
+
+// listFieldNames reads every secret selected by opts and prints the distinct field names
+// used across them, most used first, with how many of the secrets read use each. With
+// --jsonl each field is a JSON object instead of a table row.
+func listFieldNames(ctx context.Context, cmd *cli.Command, opts kv.WalkOptions, out io.Writer) error {
+	client := vaultclient.GetVaultClient(ctx)
+
+	var index kv.FieldIndex
+	err := kv.ExportSecrets(ctx, client, opts, func(_ string, data map[string]interface{}) error {
+		index.Add(data)
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to read secrets", "error", err)
+		return err
+	}
+
+	total := index.Secrets()
+	if cmd.Bool("jsonl") {
+		encoder := json.NewEncoder(out)
+		for _, field := range index.Counts() {
+			if err := encoder.Encode(map[string]interface{}{
+				"mount":   kv.NormalizeMount(opts.Mount),
+				"field":   field.Name,
+				"secrets": field.Secrets,
+				"total":   total,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, field := range index.Counts() {
+		fmt.Fprintf(w, "%s\t%d/%d\n", field.Name, field.Secrets, total)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// metricsFileFlag returns the --metrics-file flag for commands run as scheduled jobs.
+func metricsFileFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "metrics-file",
+		Usage: "Write run metrics in Prometheus text format to this file, e.g. for the node exporter textfile collector",
+	}
+}
+
+// writeCopyMetrics writes the outcome of a copy run to path in the Prometheus text
+// exposition format. result may be nil when the run failed before copying anything, and
+// runErr is the error the run ended with, if any.
+//
+// The file is written to a temporary file next to path and renamed into place, so a
+// collector scraping it never reads a partial file.
+func writeCopyMetrics(path string, result *kv.CopyResult, duration time.Duration, runErr error) error {
+	if result == nil {
+		result = &kv.CopyResult{}
+	}
+	success := 1
+	if runErr != nil {
+		success = 0
+	}
+
+	var b strings.Builder
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("vaultx_secrets_copied_total", "counter", "Secrets written to the target in the last copy run.", len(result.Copied))
+	metric("vaultx_secrets_skipped_total", "counter", "Secrets left untouched in the last copy run.", len(result.Skipped))
+	metric("vaultx_copy_errors_total", "counter", "Secrets that failed to copy in the last copy run.", len(result.Failed))
+	metric("vaultx_copy_duration_seconds", "gauge", "Wall-clock duration of the last copy run.", duration.Seconds())
+	metric("vaultx_copy_success", "gauge", "Whether the last copy run finished without a run-level error.", success)
+	metric("vaultx_copy_last_run_timestamp_seconds", "gauge", "Unix time the last copy run finished.", time.Now().Unix())
+
+	return writeFileAtomic(path, []byte(b.String()), 0o644)
+}
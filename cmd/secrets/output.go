@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+)
+
+// outFlag returns the --out flag of commands that print their results to stdout.
+func outFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "out",
+		Usage: "Write the output to this file instead of stdout; the file is only replaced once the command succeeds",
+	}
+}
+
+// output is where a command writes its results: stdout, or a temporary file next to
+// the --out path that Commit renames into place. Until then an existing file at that
+// path is left untouched, so a command failing halfway never truncates it.
+type output struct {
+	io.Writer
+	tmp  *os.File
+	path string
+	perm os.FileMode
+	done bool
+}
+
+// openOutput returns an output writing to stdout when path is empty, or to a temporary
+// file that Commit renames over path with the given permissions. Close must be called,
+// typically deferred, to remove the temporary file if Commit is never reached.
+func openOutput(path string, perm os.FileMode) (*output, error) {
+	if path == "" {
+		return &output{Writer: os.Stdout}, nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return &output{Writer: tmp, tmp: tmp, path: path, perm: perm}, nil
+}
+
+// Commit replaces the output file with everything written so far. It does nothing for
+// stdout.
+func (o *output) Commit() error {
+	if o.tmp == nil || o.done {
+		return nil
+	}
+	o.done = true
+	defer os.Remove(o.tmp.Name())
+
+	if err := o.tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Chmod(o.tmp.Name(), o.perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := os.Rename(o.tmp.Name(), o.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", o.path, err)
+	}
+	return nil
+}
+
+// Close discards the temporary file if the output was not committed.
+func (o *output) Close() {
+	if o.tmp == nil || o.done {
+		return
+	}
+	o.done = true
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+}
+
+// writeFileAtomic writes data to a temporary file in path's directory and renames it
+// over path with the given permissions.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	out, err := openOutput(path, perm)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return out.Commit()
+}
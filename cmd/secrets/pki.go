@@ -0,0 +1,254 @@
+/*
+Package secrets implements the "pki" subcommand under the "secrets" command in the vaultx CLI.
+
+The "pki" command issues certificates from a PKI engine role and reads issued certificates back
+by serial number, for certificate automation with the same client setup as the KV commands.
+
+Usage:
+  vaultx secrets pki issue --role=<role> --common-name=<cn> [--ttl=<duration>] [--cert-file=<path>] [--key-file=<path>] [--chain-file=<path>]
+  vaultx secrets pki read --serial=<serial> [--cert-file=<path>]
+
+Flags:
+  --mount         Mount path of the PKI engine (default "pki").
+  --role          Role to issue the certificate from.
+  --common-name   Common name of the requested certificate.
+  --alt-names     Subject alternative names (repeatable or comma-separated).
+  --ttl           Requested certificate lifetime, e.g. 72h. Defaults to the role's TTL.
+  --format        pem, pem_bundle or der (default "pem").
+  --serial        Serial number of the certificate to read, e.g. 39:dd:2e:...
+  --cert-file     Write the certificate to this file.
+  --key-file      Write the private key to this file (readable by the current user only, even
+                  if it already existed). Required with --cert-file or --chain-file, since
+                  Vault returns the key only once.
+  --chain-file    Write the CA chain to this file.
+
+Files are replaced atomically. Without any of the file flags, everything is printed to stdout;
+with --key-file, the certificate and chain are printed unless they have files of their own. With --format=der Vault returns
+base64-encoded DER; it is decoded to raw bytes when written to files.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/urfave/cli/v3"
+)
+
+func PKICommand() *cli.Command {
+	return &cli.Command{
+		Name:  "pki",
+		Usage: "Issue and read certificates with the PKI engine",
+		Commands: []*cli.Command{
+			{
+				Name:  "issue",
+				Usage: "Issue a certificate from a PKI role",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "mount",
+						Value: "pki",
+					},
+					&cli.StringFlag{
+						Name: "role",
+					},
+					&cli.StringFlag{
+						Name: "common-name",
+					},
+					&cli.StringSliceFlag{
+						Name:  "alt-names",
+						Usage: "Subject alternative names (repeatable or comma-separated)",
+					},
+					&cli.StringFlag{
+						Name:  "ttl",
+						Usage: "Requested certificate lifetime, e.g. 72h (defaults to the role's TTL)",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "pem",
+						Usage: "Certificate format: pem, pem_bundle or der",
+					},
+					&cli.StringFlag{
+						Name: "cert-file",
+					},
+					&cli.StringFlag{
+						Name: "key-file",
+					},
+					&cli.StringFlag{
+						Name: "chain-file",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return PKIIssue(ctx, cmd)
+				},
+			},
+			{
+				Name:  "read",
+				Usage: "Read an issued certificate by serial number",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "mount",
+						Value: "pki",
+					},
+					&cli.StringFlag{
+						Name: "serial",
+					},
+					&cli.StringFlag{
+						Name: "cert-file",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return PKIRead(ctx, cmd)
+				},
+			},
+		},
+	}
+}
+
+// PKIIssue issues a certificate from --role and writes the certificate, private key and
+// CA chain to the requested files, or to stdout.
+func PKIIssue(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	role := cmd.String("role")
+	if role == "" {
+		slog.Error("--role flag is required")
+		os.Exit(1)
+	}
+	commonName := cmd.String("common-name")
+	if commonName == "" {
+		slog.Error("--common-name flag is required")
+		os.Exit(1)
+	}
+	format := cmd.String("format")
+	switch format {
+	case "pem", "pem_bundle", "der":
+	default:
+		slog.Error("--format must be pem, pem_bundle or der", "value", format)
+		os.Exit(1)
+	}
+	// Vault returns the private key only in this response, so it must not be dropped
+	// because only the certificate was sent to a file
+	certFile, keyFile, chainFile := cmd.String("cert-file"), cmd.String("key-file"), cmd.String("chain-file")
+	if (certFile != "" || chainFile != "") && keyFile == "" {
+		slog.Error("--key-file is required with --cert-file or --chain-file, or the private key would be lost")
+		os.Exit(1)
+	}
+
+	req := schema.PkiIssueWithRoleRequest{
+		CommonName: commonName,
+		AltNames:   strings.Join(cmd.StringSlice("alt-names"), ","),
+		Ttl:        cmd.String("ttl"),
+		Format:     format,
+	}
+	resp, err := client.Secrets.PkiIssueWithRole(ctx, role, req, vault.WithMountPath(cmd.String("mount")))
+	if err != nil {
+		slog.Error("failed to issue certificate", "role", role, "error", err)
+		return err
+	}
+	cert := resp.Data
+	logging.RegisterSecretValues(map[string]interface{}{"private_key": cert.PrivateKey})
+
+	if cert.Certificate == "" {
+		return errors.New("pki issue response did not contain a certificate")
+	}
+
+	if keyFile == "" {
+		fmt.Println(cert.Certificate)
+		fmt.Println(cert.PrivateKey)
+		for _, ca := range cert.CaChain {
+			fmt.Println(ca)
+		}
+		return nil
+	}
+
+	// the key goes first, so it is saved even if another file can't be written
+	outputs := []struct {
+		file   string
+		values []string
+		perm   os.FileMode
+	}{
+		{keyFile, []string{cert.PrivateKey}, 0o600},
+		{certFile, []string{cert.Certificate}, 0o644},
+		{chainFile, cert.CaChain, 0o644},
+	}
+	for _, out := range outputs {
+		if out.file == "" {
+			for _, value := range out.values {
+				fmt.Println(value)
+			}
+			continue
+		}
+		if err := writeCertFile(out.file, out.values, format, out.perm); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("certificate issued", "serial", cert.SerialNumber, "expiration", cert.Expiration)
+	return nil
+}
+
+// PKIRead reads the certificate with --serial and writes it as PEM to --cert-file or
+// stdout.
+func PKIRead(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	serial := cmd.String("serial")
+	if serial == "" {
+		slog.Error("--serial flag is required")
+		os.Exit(1)
+	}
+
+	resp, err := client.Secrets.PkiReadCert(ctx, serial, vault.WithMountPath(cmd.String("mount")))
+	if err != nil {
+		slog.Error("failed to read certificate", "serial", serial, "error", err)
+		return err
+	}
+	if resp.Data.RevocationTime != 0 {
+		slog.Warn("certificate has been revoked", "serial", serial, "revocation_time", resp.Data.RevocationTimeRfc3339)
+	}
+
+	if certFile := cmd.String("cert-file"); certFile != "" {
+		return writeCertFile(certFile, []string{resp.Data.Certificate}, "pem", 0o644)
+	}
+	fmt.Println(resp.Data.Certificate)
+	return nil
+}
+
+// writeCertFile writes values, as returned by Vault in the given format, to file with
+// the given permissions, which also apply when it replaces an existing file. PEM blocks
+// are written one after another; base64 DER is decoded to raw bytes.
+func writeCertFile(file string, values []string, format string, perm os.FileMode) error {
+	var out []byte
+	for _, value := range values {
+		if format == "der" {
+			der, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return fmt.Errorf("failed to decode DER for %s: %w", file, err)
+			}
+			out = append(out, der...)
+			continue
+		}
+		out = append(out, strings.TrimSpace(value)+"\n"...)
+	}
+
+	if err := writeFileAtomic(file, out, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
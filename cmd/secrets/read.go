@@ -0,0 +1,150 @@
+/*
+Package secrets implements the "read" subcommand under the "secrets" command in the vaultx CLI.
+
+The "read" command reads a single secret and prints its data as JSON. The KV engine version and
+mount are detected from the secret path, exactly as for "create".
+
+Usage:
+  vaultx secrets read --path=<mount/path> [--keys-only] [--out=<file>]
+
+Flags:
+  --path                 Full secret path, including the mount (e.g. secret/app/db).
+  --keys-only            Print only the field names present in the secret, never the values.
+  --kv-version           Force KV v1 or v2 behavior instead of detecting it from the mount.
+  --base64-decode-keys   Base64-decode these fields, which must hold encoded text such as PEM, before printing them.
+  --dereference          Replace ref:<mount>/<path>#<field> values with the referenced field's value.
+  --out                  Write the output to this file instead of stdout.
+
+--keys-only is intended for demos, terminal sessions and CI logs where values must not leak.
+With --out the file is created readable by the current user only, and only replaced once the
+secret was read successfully.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func ReadCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "read",
+		Usage: "Read a secret",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "path",
+			},
+			&cli.BoolFlag{
+				Name:  "keys-only",
+				Usage: "Print only field names, not values",
+			},
+			kvVersionFlag(),
+			&cli.StringSliceFlag{
+				Name:  "base64-decode-keys",
+				Usage: "Base64-decode the values of these fields, which must decode to text such as PEM (repeatable or comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "dereference",
+				Usage: "Replace ref:<mount>/<path>#<field> values with the referenced field's value",
+			},
+			outFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return ReadSecret(ctx, cmd)
+		},
+	}
+}
+
+// ReadSecret reads the secret at --path and prints its data as indented JSON, or only
+// its sorted field names when --keys-only is set, to stdout or the --out file.
+func ReadSecret(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	secretPath := cmd.String("path")
+	if secretPath == "" {
+		slog.Error("--path flag is required")
+		os.Exit(1)
+	}
+
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	mountInfo, relativePath, err := findMount(ctx, client, secretPath, kvVersion)
+	if err != nil {
+		slog.Error("mount not found for secret", "path", secretPath, "error", err)
+		return err
+	}
+
+	data, err := kv.ReadSecret(ctx, client, nil, mountInfo, relativePath)
+	if err != nil {
+		slog.Error("failed to read secret", "path", secretPath, "error", err)
+		return err
+	}
+
+	if cmd.Bool("dereference") {
+		// references may point at any mount, so resolving them needs the mount list
+		mountsMap, err := kv.GetSecretEngines(ctx, client)
+		if err != nil {
+			slog.Error("unable to list KV secret engines for dereferencing", "error", err)
+			return err
+		}
+		if kvVersion != "" {
+			for mountPath, mountInfo := range mountsMap {
+				mountInfo.Version = kvVersion
+				mountsMap[mountPath] = mountInfo
+			}
+		}
+		if data, err = kv.DereferenceSecret(ctx, client, mountsMap, secretPath, data); err != nil {
+			slog.Error("failed to dereference secret", "path", secretPath, "error", err)
+			return err
+		}
+	}
+
+	if err := kv.DecodeBase64TextFields(data, cmd.StringSlice("base64-decode-keys")); err != nil {
+		slog.Error("failed to decode secret fields", "path", secretPath, "error", err)
+		return err
+	}
+
+	out, err := openOutput(cmd.String("out"), 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if cmd.Bool("keys-only") {
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, err := fmt.Fprintln(out, key); err != nil {
+				return err
+			}
+		}
+		return out.Commit()
+	}
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(out, string(encoded)); err != nil {
+		return err
+	}
+	return out.Commit()
+}
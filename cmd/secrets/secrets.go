@@ -9,8 +9,18 @@ Usage hierarchy:
   vaultx secrets [subcommand]
 
 Available subcommands:
-  copy    - Copy secrets between locations or formats.
-  create  - Create new secrets with specified parameters.
+  copy     - Copy secrets between locations or formats.
+  create   - Create new secrets with specified parameters.
+  delete   - Delete a secret, or a subtree with --recursive.
+  export   - Export the secrets under a mount as JSON.
+  generate - Write a secret with randomly generated values.
+  lease    - Renew or revoke leases on dynamic secrets.
+  list     - List secret paths under a mount.
+  pki      - Issue and read certificates with the PKI engine.
+  read     - Read a secret.
+  set      - Write a secret from key=value arguments.
+  touch    - Create a new KV v2 version of a secret without changing it.
+  transit  - Encrypt or decrypt data with the transit engine.
 
 This package integrates with urfave/cli to expose structured and extensible CLI behavior.
 */
@@ -18,15 +28,31 @@ This package integrates with urfave/cli to expose structured and extensible CLI
 package secrets
 
 import (
+	"context"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
 	"github.com/urfave/cli/v3"
 )
 
 func SecretsCommand() *cli.Command {
 	return &cli.Command{
 		Name: "secrets",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
 		Commands: []*cli.Command{
 			CopyCommand(),
 			CreateCommand(),
+			DeleteCommand(),
+			ExportCommand(),
+			GenerateCommand(),
+			LeaseCommand(),
+			ListCommand(),
+			PKICommand(),
+			ReadCommand(),
+			SetCommand(),
+			TouchCommand(),
+			TransitCommand(),
 		},
 	}
 }
@@ -0,0 +1,127 @@
+/*
+Package secrets implements the "set" subcommand under the "secrets" command in the vaultx CLI.
+
+The "set" command writes a single secret from key=value arguments, mirroring "vault kv put", for
+quick one-off writes and scripts that don't warrant an input file. The KV engine version and
+mount are detected from the secret path, exactly as for "create".
+
+Usage:
+  vaultx secrets set --path=<mount/path> <key>=<value> [<key>=@<file> ...]
+
+Flags:
+  --path        Full secret path, including the mount (e.g. secret/app/db).
+  --kv-version  Force KV v1 or v2 behavior instead of detecting it from the mount.
+
+A value starting with @ is read from the named file, so multi-line values such as certificates
+stay out of shell history. The written secret holds exactly the given keys, replacing any
+previous data at the path.
+*/
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func SetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "set",
+		Usage:     "Write a secret from key=value arguments",
+		ArgsUsage: "<key>=<value> [<key>=@<file> ...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "path",
+			},
+			kvVersionFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return SetSecret(ctx, cmd)
+		},
+	}
+}
+
+// SetSecret writes the key=value arguments as the secret at --path.
+func SetSecret(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	secretPath := cmd.String("path")
+	if secretPath == "" {
+		slog.Error("--path flag is required")
+		os.Exit(1)
+	}
+	if cmd.Args().Len() == 0 {
+		slog.Error("at least one key=value argument is required")
+		os.Exit(1)
+	}
+
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	data, err := parseKeyValues(cmd.Args().Slice())
+	if err != nil {
+		slog.Error("invalid key=value argument", "error", err)
+		os.Exit(1)
+	}
+
+	mountInfo, relativePath, err := findMount(ctx, client, secretPath, kvVersion)
+	if err != nil {
+		slog.Error("mount not found for secret", "path", secretPath, "error", err)
+		return err
+	}
+
+	version, err := kv.WriteSecret(ctx, client, nil, mountInfo, relativePath, data)
+	if err != nil {
+		slog.Error("failed to write secret", "path", secretPath, "error", err)
+		return err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	slog.Info("secret written", "path", secretPath, "keys", keys, "version", version)
+	return nil
+}
+
+// parseKeyValues turns key=value arguments into secret data. A value of the form @file
+// is replaced by the file's contents. Empty and repeated keys are rejected.
+func parseKeyValues(args []string) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not in key=value form", arg)
+		}
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%q has an empty key", arg)
+		}
+		if _, dup := data[key]; dup {
+			return nil, fmt.Errorf("key %q is given more than once", key)
+		}
+
+		if file, ok := strings.CutPrefix(value, "@"); ok {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			value = string(content)
+		}
+		data[key] = value
+	}
+	return data, nil
+}
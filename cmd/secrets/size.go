@@ -0,0 +1,15 @@
+package secrets
+
+import (
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+// maxSecretSizeFlag returns the --max-secret-size flag shared by create and copy.
+func maxSecretSizeFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:  "max-secret-size",
+		Value: kv.DefaultMaxSecretSize,
+		Usage: "Warn about secrets whose JSON data exceeds this many bytes, which Vault's storage would reject (0 disables the check)",
+	}
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// decryptInput returns raw, the contents of filePath, decrypted as --decrypt asks: as
+// is when decrypt is empty, or by the sops binary when it is "sops". format is the
+// resolved input format, passed to sops so its output parses as the same format.
+func decryptInput(ctx context.Context, decrypt, filePath, format string, raw []byte) ([]byte, error) {
+	switch decrypt {
+	case "":
+		return raw, nil
+	case "sops":
+		return decryptSOPS(ctx, filePath, format, raw)
+	default:
+		return nil, fmt.Errorf("--decrypt must be sops, got %q", decrypt)
+	}
+}
+
+// decryptSOPS runs "sops --decrypt" on filePath. sops needs a file to read, so stdin
+// ("-") is first copied to a temporary file; it holds the still-encrypted input only.
+// The decrypted output is read from sops' stdout and never touches disk.
+func decryptSOPS(ctx context.Context, filePath, format string, raw []byte) ([]byte, error) {
+	bin, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("--decrypt sops requires the sops binary on PATH: %w", err)
+	}
+
+	if filePath == "-" {
+		tmp, err := os.CreateTemp("", "vaultx-sops-*."+format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.Write(raw)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write temporary file: %w", err)
+		}
+		filePath = tmp.Name()
+	}
+
+	var stdout, stderr bytes.Buffer
+	sops := exec.CommandContext(ctx, bin, "--decrypt", "--input-type", format, "--output-type", format, filePath)
+	sops.Stdout = &stdout
+	sops.Stderr = &stderr
+	if err := sops.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops failed to decrypt the input: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("sops failed to decrypt the input: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// errSOPSEncrypted is returned for input that still holds SOPS metadata, which would
+// otherwise be written to Vault as a secret named "sops" next to encrypted values.
+var errSOPSEncrypted = errors.New("input looks SOPS-encrypted; pass --decrypt sops to decrypt it first")
+
+// isSOPSMetadata reports whether v, the value of a top-level "sops" key, is the
+// metadata SOPS adds to the files it encrypts.
+func isSOPSMetadata(v interface{}) bool {
+	metadata, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasMAC := metadata["mac"]
+	return hasMAC
+}
@@ -0,0 +1,130 @@
+/*
+Package secrets implements the "touch" subcommand under the "secrets" command in the vaultx CLI.
+
+The "touch" command rewrites a KV v2 secret with its current data, creating a new version without
+changing anything, e.g. to reset rotation timers that key off a version's creation time. With
+--recursive every secret under the path is touched.
+
+Usage:
+  vaultx secrets touch --path=<mount/path>
+  vaultx secrets touch --path=<mount/path/> --recursive
+
+Flags:
+  --path        Full secret path, including the mount (e.g. secret/app/db).
+  --recursive   Touch every secret under --path instead of a single secret.
+  --kv-version  Force KV v1 or v2 behavior instead of detecting it from the mount.
+
+Each rewrite is a check-and-set against the version just read, so a secret changed in the
+meantime is reported as failed rather than reverted. KV v1 mounts keep no versions and are
+rejected.
+*/
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/razahuss02/vaultx/internal/color"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	kv "github.com/razahuss02/vaultx/pkg/secrets"
+	"github.com/urfave/cli/v3"
+)
+
+func TouchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "touch",
+		Usage: "Create a new KV v2 version of a secret without changing its data",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "path",
+			},
+			&cli.BoolFlag{
+				Name:  "recursive",
+				Usage: "Touch every secret under --path",
+			},
+			kvVersionFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return TouchSecrets(ctx, cmd)
+		},
+	}
+}
+
+// TouchSecrets touches the secret at --path or, with --recursive, every secret under it.
+func TouchSecrets(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	secretPath := cmd.String("path")
+	if secretPath == "" {
+		return errors.New("--path flag is required")
+	}
+	recursive := cmd.Bool("recursive")
+	if !recursive && strings.HasSuffix(secretPath, "/") {
+		return fmt.Errorf("--path %q names a directory; pass --recursive to touch every secret under it", secretPath)
+	}
+
+	kvVersion, err := kvVersionOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	mountInfo, relativePath, err := findMount(ctx, client, secretPath, kvVersion)
+	if err != nil {
+		return err
+	}
+	if mountInfo.Version != "2" {
+		return fmt.Errorf("%w: touch needs KV v2 versions, %q is KV v%s", kv.ErrUnsupportedKVVersion, mountInfo.MountPath, mountInfo.Version)
+	}
+
+	if !recursive {
+		if relativePath == "" {
+			return fmt.Errorf("--path %q names a mount, not a secret", secretPath)
+		}
+		version, err := kv.TouchSecret(ctx, client, nil, mountInfo, relativePath)
+		if err != nil {
+			slog.Error("failed to touch secret", "path", secretPath, "error", err)
+			return err
+		}
+		slog.Info("secret touched", "path", secretPath, "version", version)
+		return nil
+	}
+
+	// touch each secret as it is found, rather than listing the subtree first
+	var touched, failed int
+	err = kv.WalkSecrets(ctx, client, kv.WalkOptions{
+		Mount:     mountInfo.MountPath,
+		Prefix:    relativePath,
+		KVVersion: mountInfo.Version,
+	}, func(fullPath string) error {
+		rel := kv.RelativeSecretPath(mountInfo.MountPath, fullPath)
+		version, err := kv.TouchSecret(ctx, client, nil, mountInfo, rel)
+		if err != nil {
+			slog.Error("failed to touch secret", "path", fullPath, "error", err)
+			failed++
+			return nil
+		}
+		slog.Info("secret touched", "path", fullPath, "version", version)
+		touched++
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to list secrets", "path", secretPath, "error", err)
+		return err
+	}
+
+	fmt.Println(color.Summary("touch finished",
+		color.Count{Label: "touched", N: touched, Paint: color.Green},
+		color.Count{Label: "failed", N: failed, Paint: color.Red},
+	))
+	if failed > 0 {
+		return errors.New("some secrets could not be touched")
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Package secrets implements the "transit" subcommand under the "secrets" command in the vaultx CLI.
+
+The "transit" command exposes Vault's transit engine as a lightweight encryption-as-a-service
+client. Plaintext is base64-encoded before it is sent to Vault and decoded again on decrypt,
+so callers always work with raw bytes.
+
+Usage:
+  vaultx secrets transit encrypt --key=<key-name> [--plaintext=<text> | --file=<path>]
+  vaultx secrets transit decrypt --key=<key-name> [--ciphertext=<text> | --file=<path>]
+
+Flags:
+  --key          Name of the transit key.
+  --mount        Mount path of the transit engine (default "transit").
+  --plaintext    Data to encrypt. Read from stdin when neither --plaintext nor --file is set.
+  --ciphertext   Ciphertext to decrypt. Read from stdin when neither --ciphertext nor --file is set.
+  --file, -f     Read the input data from a file.
+
+This subcommand reuses the same client setup as the KV commands.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/urfave/cli/v3"
+)
+
+func TransitCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "transit",
+		Usage: "Encrypt or decrypt data with the transit engine",
+		Commands: []*cli.Command{
+			{
+				Name:  "encrypt",
+				Usage: "Encrypt data with a transit key",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "key",
+					},
+					&cli.StringFlag{
+						Name:  "mount",
+						Value: "transit",
+					},
+					&cli.StringFlag{
+						Name: "plaintext",
+					},
+					&cli.StringFlag{
+						Name:    "file",
+						Aliases: []string{"f"},
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return TransitEncrypt(ctx, cmd)
+				},
+			},
+			{
+				Name:  "decrypt",
+				Usage: "Decrypt data with a transit key",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "key",
+					},
+					&cli.StringFlag{
+						Name:  "mount",
+						Value: "transit",
+					},
+					&cli.StringFlag{
+						Name: "ciphertext",
+					},
+					&cli.StringFlag{
+						Name:    "file",
+						Aliases: []string{"f"},
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return TransitDecrypt(ctx, cmd)
+				},
+			},
+		},
+	}
+}
+
+// TransitEncrypt encrypts the input data with the named transit key and prints the
+// resulting "vault:v<n>:..." ciphertext to stdout.
+func TransitEncrypt(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	key := cmd.String("key")
+	if key == "" {
+		slog.Error("--key flag is required")
+		os.Exit(1)
+	}
+
+	data, err := readTransitInput(cmd.String("plaintext"), cmd.String("file"))
+	if err != nil {
+		slog.Error("failed to read input", "error", err)
+		os.Exit(1)
+	}
+
+	req := schema.TransitEncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(data),
+	}
+	resp, err := client.Secrets.TransitEncrypt(ctx, key, req, vault.WithMountPath(cmd.String("mount")))
+	if err != nil {
+		slog.Error("failed to encrypt data", "key", key, "error", err)
+		return err
+	}
+
+	ciphertext, ok := resp.Data["ciphertext"].(string)
+	if !ok {
+		return errors.New("transit encrypt response did not contain ciphertext")
+	}
+
+	fmt.Println(ciphertext)
+	return nil
+}
+
+// TransitDecrypt decrypts the input ciphertext with the named transit key and writes
+// the decoded plaintext bytes to stdout.
+func TransitDecrypt(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	key := cmd.String("key")
+	if key == "" {
+		slog.Error("--key flag is required")
+		os.Exit(1)
+	}
+
+	data, err := readTransitInput(cmd.String("ciphertext"), cmd.String("file"))
+	if err != nil {
+		slog.Error("failed to read input", "error", err)
+		os.Exit(1)
+	}
+
+	req := schema.TransitDecryptRequest{
+		Ciphertext: strings.TrimSpace(string(data)),
+	}
+	resp, err := client.Secrets.TransitDecrypt(ctx, key, req, vault.WithMountPath(cmd.String("mount")))
+	if err != nil {
+		slog.Error("failed to decrypt data", "key", key, "error", err)
+		return err
+	}
+
+	encoded, ok := resp.Data["plaintext"].(string)
+	if !ok {
+		return errors.New("transit decrypt response did not contain plaintext")
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode plaintext: %w", err)
+	}
+
+	_, err = os.Stdout.Write(plaintext)
+	return err
+}
+
+// readTransitInput returns the inline value if set, otherwise the contents of filePath,
+// otherwise everything available on stdin.
+func readTransitInput(inline, filePath string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if filePath != "" {
+		return os.ReadFile(filePath)
+	}
+	return io.ReadAll(os.Stdin)
+}
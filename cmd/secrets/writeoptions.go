@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// writeOptionsFlag returns the --write-options flag shared by the commands that write
+// KV v2 secrets.
+func writeOptionsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:  "write-options",
+		Usage: "KV v2 write options as key=value pairs, e.g. cas=0 (repeatable or comma-separated)",
+	}
+}
+
+// writeOptions returns the parsed --write-options, or nil when none are given. Integer
+// values are sent as JSON numbers and true/false as booleans, since Vault expects
+// options like cas to be numeric; anything else is sent as a string.
+func writeOptions(cmd *cli.Command) (map[string]interface{}, error) {
+	pairs := cmd.StringSlice("write-options")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	options := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--write-options: %q is not in key=value form", pair)
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			options[key] = n
+		} else if value == "true" || value == "false" {
+			options[key] = value == "true"
+		} else {
+			options[key] = value
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,138 @@
+/*
+Package token defines the "token" command for the vaultx CLI.
+
+The token command reports on the token vaultx authenticates with, which is the first thing to
+check when a copy fails with a permission error.
+
+Usage hierarchy:
+  vaultx token [subcommand]
+  vaultx whoami
+
+Available subcommands:
+  lookup   - Print the current token's identity, policies, TTL and renewability.
+
+Usage:
+  vaultx token lookup [--json]
+  vaultx whoami [--json]
+
+Flags:
+  --json   Print the token details as JSON instead of a table.
+
+The token itself is never printed.
+*/
+
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"github.com/urfave/cli/v3"
+)
+
+func TokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Inspect the current Vault token",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return vaultclient.InitVaultContext(ctx)
+		},
+		Commands: []*cli.Command{
+			LookupCommand(),
+		},
+	}
+}
+
+// WhoamiCommand is a top-level shortcut for "token lookup".
+func WhoamiCommand() *cli.Command {
+	lookup := LookupCommand()
+	lookup.Name = "whoami"
+	lookup.Usage = "Show which token vaultx is operating as (same as \"token lookup\")"
+	lookup.Before = func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		return vaultclient.InitVaultContext(ctx)
+	}
+	return lookup
+}
+
+func LookupCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lookup",
+		Usage: "Print the current token's accessor, policies, TTL and renewable status",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the token details as JSON",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return LookupToken(ctx, cmd)
+		},
+	}
+}
+
+// tokenInfo is the subset of the lookup-self response that identifies a token. The
+// token ID is deliberately left out.
+type tokenInfo struct {
+	DisplayName string   `json:"display_name"`
+	EntityID    string   `json:"entity_id"`
+	Accessor    string   `json:"accessor"`
+	Policies    []string `json:"policies"`
+	TTL         int64    `json:"ttl"`
+	ExpireTime  string   `json:"expire_time"`
+	Renewable   bool     `json:"renewable"`
+}
+
+// LookupToken looks up the current token and prints its details as a table, or as JSON
+// with --json.
+func LookupToken(ctx context.Context, cmd *cli.Command) error {
+	client := vaultclient.GetVaultClient(ctx)
+	if client == nil {
+		return vaultclient.ErrVaultClientMissing
+	}
+
+	resp, err := client.Auth.TokenLookUpSelf(ctx)
+	if err != nil {
+		slog.Error("failed to look up token", "error", err)
+		return err
+	}
+
+	// round-trip through JSON to decode the untyped response into tokenInfo
+	raw, err := json.Marshal(resp.Data)
+	if err != nil {
+		return err
+	}
+	var info tokenInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return fmt.Errorf("unexpected token lookup response: %w", err)
+	}
+
+	if cmd.Bool("json") {
+		out, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	ttl := (time.Duration(info.TTL) * time.Second).String()
+	if info.TTL == 0 {
+		ttl = "never expires"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "display_name\t%s\n", info.DisplayName)
+	fmt.Fprintf(w, "entity_id\t%s\n", info.EntityID)
+	fmt.Fprintf(w, "accessor\t%s\n", info.Accessor)
+	fmt.Fprintf(w, "policies\t%s\n", strings.Join(info.Policies, ", "))
+	fmt.Fprintf(w, "ttl\t%s\n", ttl)
+	fmt.Fprintf(w, "renewable\t%t\n", info.Renewable)
+	return w.Flush()
+}
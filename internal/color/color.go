@@ -0,0 +1,72 @@
+/*
+Package color adds ANSI colors to the human-facing output of the vaultx CLI.
+
+Operation summaries print successes in green, failures in red and skipped items in yellow.
+Coloring is only applied when stdout is a terminal, and is turned off entirely by the global
+--no-color flag or the NO_COLOR environment variable (https://no-color.org). Machine-readable
+output such as JSON and JSON lines is never colored.
+*/
+
+package color
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	reset  = "\x1b[0m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+)
+
+var enabled bool
+
+// Setup decides whether output is colored. noColor forces coloring off.
+func Setup(noColor bool) {
+	enabled = !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+}
+
+// Green marks s as a success.
+func Green(s string) string { return paint(green, s) }
+
+// Red marks s as a failure.
+func Red(s string) string { return paint(red, s) }
+
+// Yellow marks s as skipped.
+func Yellow(s string) string { return paint(yellow, s) }
+
+// Count is one labelled number in a summary line.
+type Count struct {
+	Label string
+	N     int
+	Paint func(string) string
+}
+
+// Summary formats an operation summary such as "create finished: 3 written, 1 skipped".
+// Zero counts are left uncolored so that only what needs attention stands out.
+func Summary(operation string, counts ...Count) string {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		part := fmt.Sprintf("%d %s", c.N, c.Label)
+		if c.N > 0 && c.Paint != nil {
+			part = c.Paint(part)
+		}
+		parts[i] = part
+	}
+	return operation + ": " + strings.Join(parts, ", ")
+}
+
+func paint(code, s string) string {
+	if !enabled {
+		return s
+	}
+	return code + s + reset
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
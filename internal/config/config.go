@@ -0,0 +1,208 @@
+/*
+Package config loads the optional vaultx configuration file.
+
+The configuration file (by default ~/.vaultx.yaml, overridable with --config) sets defaults
+that would otherwise have to be passed on every invocation, and defines named Vault
+environments that the client can connect to.
+
+Example:
+
+	log-level: info
+	default-mount: secret
+	environment: dev
+	environments:
+	  dev:
+	    addr: https://vault.dev.example.com
+	    token-env: VAULT_DEV_TOKEN
+	  prod:
+	    addr: https://vault.example.com
+	    token-file: ~/.vault-token-prod
+
+Precedence is flags, then environment variables, then the configuration file. The global
+--context flag selects a different environment for a single invocation and, being a flag,
+its address and token override VAULT_ADDR and VAULT_TOKEN; "vaultx context use" changes the
+persisted selection, which only fills in what the environment variables leave unset.
+
+A missing default configuration file is not an error; an explicitly requested file that
+cannot be read is.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+)
+
+// DefaultFileName is the name of the configuration file looked up in the user's home directory.
+const DefaultFileName = ".vaultx.yaml"
+
+type ctxKey string
+
+const configKey ctxKey = "config"
+
+// Config holds the settings read from the configuration file.
+type Config struct {
+	LogLevel     string                 `koanf:"log-level"`
+	DefaultMount string                 `koanf:"default-mount"`
+	Environment  string                 `koanf:"environment"`
+	Environments map[string]Environment `koanf:"environments"`
+
+	// Context is the environment selected for this invocation with --context. Unlike
+	// Environment, which only fills in what VAULT_ADDR and VAULT_TOKEN leave unset, it
+	// overrides them.
+	Context string `koanf:"-"`
+	// Path is the file the configuration was loaded from, or empty if none was found.
+	Path string `koanf:"-"`
+}
+
+// Environment describes how to reach a named Vault instance.
+//
+// The token is taken from Token if set, otherwise from the environment variable named
+// by TokenEnv, otherwise from the file at TokenFile.
+type Environment struct {
+	Addr      string `koanf:"addr"`
+	Token     string `koanf:"token"`
+	TokenEnv  string `koanf:"token-env"`
+	TokenFile string `koanf:"token-file"`
+}
+
+// DefaultPath returns the path of the configuration file in the user's home directory.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, DefaultFileName), nil
+}
+
+// Load reads the configuration file at path. If path is empty the default location is
+// used and a missing file yields an empty Config.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		defaultPath, err := DefaultPath()
+		if err != nil {
+			return &Config{}, nil
+		}
+		path = defaultPath
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("unable to read config file %q: %w", path, err)
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %q: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if err := k.Unmarshal("", cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+	cfg.Path = path
+
+	return cfg, nil
+}
+
+// SetEnvironment persists name as the current environment in the config file at path.
+//
+// The file is rewritten from its parsed form, so comments and key ordering are not preserved.
+func SetEnvironment(path, name string) error {
+	if path == "" {
+		return errors.New("no config file loaded")
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return fmt.Errorf("unable to parse config file %q: %w", path, err)
+	}
+	if err := k.Set("environment", name); err != nil {
+		return err
+	}
+
+	out, err := k.Marshal(yaml.Parser())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o600)
+}
+
+// CurrentName returns the name of the selected environment: Context if set, otherwise
+// the "environment" key.
+func (c *Config) CurrentName() string {
+	if c.Context != "" {
+		return c.Context
+	}
+	return c.Environment
+}
+
+// CurrentEnvironment returns the environment selected by Context or the "environment"
+// key, if any.
+func (c *Config) CurrentEnvironment() (Environment, bool, error) {
+	name := c.CurrentName()
+	if name == "" {
+		return Environment{}, false, nil
+	}
+
+	env, ok := c.Environments[name]
+	if !ok {
+		return Environment{}, false, fmt.Errorf("environment %q is not defined in config", name)
+	}
+	return env, true, nil
+}
+
+// ResolveToken returns the token for the environment according to its token source.
+func (e Environment) ResolveToken() (string, error) {
+	switch {
+	case e.Token != "":
+		return e.Token, nil
+	case e.TokenEnv != "":
+		return os.Getenv(e.TokenEnv), nil
+	case e.TokenFile != "":
+		raw, err := os.ReadFile(expandHome(e.TokenFile))
+		if err != nil {
+			return "", fmt.Errorf("unable to read token file: %w", err)
+		}
+		return strings.TrimSpace(string(raw)), nil
+	default:
+		return "", nil
+	}
+}
+
+// WithConfig returns a copy of ctx carrying cfg.
+func WithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, configKey, cfg)
+}
+
+// FromContext returns the Config stored in ctx, or an empty Config if there is none.
+func FromContext(ctx context.Context) *Config {
+	cfg, ok := ctx.Value(configKey).(*Config)
+	if !ok || cfg == nil {
+		return &Config{}
+	}
+	return cfg
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadEnvFile sets environment variables from a dotenv-style file, so connection
+// settings such as VAULT_ADDR, VAULT_TOKEN and VAULT_TARGET_* can be kept in one file
+// per environment. Each non-blank line that doesn't start with "#" must be KEY=VALUE,
+// optionally preceded by "export "; a value wrapped in matching single or double
+// quotes is unquoted.
+//
+// Variables already set in the environment are left alone, so the real environment
+// always takes precedence over the file.
+func LoadEnvFile(path string) error {
+	f, err := os.Open(expandHome(path))
+	if err != nil {
+		return fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("env file %s line %d: expected KEY=VALUE", path, lineNo)
+		}
+		value = unquote(strings.TrimSpace(value))
+
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("env file %s line %d: %w", path, lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+	return nil
+}
+
+// unquote strips one pair of matching single or double quotes around value.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
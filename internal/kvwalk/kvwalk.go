@@ -0,0 +1,134 @@
+/*
+Package kvwalk traverses the secrets stored under a KV mount.
+
+It holds the recursive LIST traversal shared by every vaultx operation that visits a subtree,
+such as list, export, copy, delete and touch, so they agree on how KV v1 and v2 are listed,
+how depth and size limits apply and how missing paths are handled. It works on a mount whose KV version
+is already known; detecting the version is left to the caller.
+
+Each LIST goes through vaultclient.WithRetry, so traversals honor the caller's rate limit, back
+off on 429 responses and re-authenticate when the token expires partway through a long walk.
+*/
+
+package kvwalk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// ErrSecretLimit is returned by WalkSecrets when a traversal finds more secrets than
+// Options.MaxSecrets allows.
+var ErrSecretLimit = errors.New("secret limit reached")
+
+// Options selects the part of a mount WalkSecrets visits.
+type Options struct {
+	// Prefix limits traversal to secrets under this path within the mount.
+	Prefix string
+	// MaxDepth is the maximum number of path levels to descend below Prefix; 1 visits
+	// only the secrets directly under it and 0 is unlimited.
+	MaxDepth int
+	// MaxSecrets aborts the traversal with ErrSecretLimit when more than this many
+	// secrets are found, after fn was called for the first MaxSecrets; 0 is unlimited.
+	MaxSecrets int
+	// Limiter, if not nil, is waited on before every LIST request.
+	Limiter *rate.Limiter
+}
+
+// WalkSecrets traverses mount, a KV mount of the given version ("1" or "2"), and calls fn
+// with the full path of each secret, including the mount, as soon as it is discovered.
+// Directories are visited depth first in the order Vault lists them. A directory that
+// disappears mid-walk (404) is logged and skipped. Traversal stops at the first error
+// returned by fn.
+func WalkSecrets(ctx context.Context, client *vault.Client, mount, version string, opts Options, fn func(secretPath string) error) error {
+	visited := 0
+
+	// depth is the number of path levels below the traversal start; 1 lists only the
+	// secrets directly under it.
+	var traverse func(string, int) error
+	traverse = func(currentPath string, depth int) error {
+		keys, err := listKeys(ctx, client, opts.Limiter, version, mount, currentPath)
+		if vault.IsErrorStatus(err, http.StatusNotFound) {
+			slog.Error("404 Not Found at:", "path", currentPath)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			full := path.Join(currentPath, key)
+			if strings.HasSuffix(key, "/") {
+				if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+					slog.Info("max depth reached, not descending", "path", path.Join(mount, full)+"/")
+					continue
+				}
+				if err := traverse(full, depth+1); err != nil {
+					return err
+				}
+			} else {
+				if opts.MaxSecrets > 0 && visited == opts.MaxSecrets {
+					return fmt.Errorf("%w: %q holds more than %d secrets, stopped after %d", ErrSecretLimit, path.Join(mount, strings.Trim(opts.Prefix, "/")), opts.MaxSecrets, visited)
+				}
+				visited++
+				if err := fn(path.Join(mount, full)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return traverse(strings.Trim(opts.Prefix, "/"), 1)
+}
+
+// listKeys returns the keys directly under currentPath, using the KV v1 LIST endpoint or,
+// for KV v2, the metadata LIST endpoint, which lists keys without reading any secret data.
+//
+// Vault's KV LIST responses are not paginated: there is no continuation token or
+// page size, and every key under the path is returned in a single response however
+// many there are. One request per directory is therefore complete, and large mounts
+// are bounded by Vault's max_request_size and response size rather than truncated.
+func listKeys(ctx context.Context, client *vault.Client, limiter *rate.Limiter, kvVersion, mount, currentPath string) ([]string, error) {
+	var keys []string
+	switch kvVersion {
+	case "1":
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			response, err := client.Secrets.KvV1List(ctx, currentPath, vault.WithMountPath(mount), opt)
+			if err == nil {
+				keys = response.Data.Keys
+			}
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("kv v1 list failed at path %q: %w", currentPath, err)
+		}
+		return keys, nil
+
+	case "2":
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			response, err := client.Secrets.KvV2List(ctx, currentPath, vault.WithMountPath(mount), opt)
+			if err == nil {
+				keys = response.Data.Keys
+			}
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("kv v2 list failed at path %q: %w", currentPath, err)
+		}
+		return keys, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", vaultclient.ErrUnsupportedKVVersion, kvVersion)
+	}
+}
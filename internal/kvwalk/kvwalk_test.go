@@ -0,0 +1,186 @@
+package kvwalk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// fakeVault serves LIST requests for the KV mount "secret" of the given version from
+// tree, which maps each directory within the mount ("" for its root) to the keys under
+// it. Directories missing from tree are answered with 404. before, if not nil, runs
+// first and may answer the request itself by returning true.
+func fakeVault(t *testing.T, version string, tree map[string][]string, before func(w http.ResponseWriter, r *http.Request) bool) *vault.Client {
+	t.Helper()
+
+	prefix := "/v1/secret/"
+	if version == "2" {
+		prefix += "metadata/"
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if before != nil && before(w, r) {
+			return
+		}
+		if r.URL.Query().Get("list") != "true" || !strings.HasPrefix(r.URL.Path, prefix) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		keys, ok := tree[strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	}))
+	t.Cleanup(srv.Close)
+
+	// the client's own retries are turned off, so 429s reach vaultclient.WithRetry
+	client, err := vault.New(vault.WithAddress(srv.URL), vault.WithRetryConfiguration(vault.RetryConfiguration{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetToken("test-token"); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// walk returns every secret path WalkSecrets visits on the mount "secret".
+func walk(client *vault.Client, version string, opts Options) ([]string, error) {
+	var visited []string
+	err := WalkSecrets(context.Background(), client, "secret", version, opts, func(secretPath string) error {
+		visited = append(visited, secretPath)
+		return nil
+	})
+	return visited, err
+}
+
+var tree = map[string][]string{
+	"":           {"app/", "top"},
+	"app":        {"db", "nested/"},
+	"app/nested": {"deep"},
+}
+
+func TestWalkSecretsRetriesRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	client := fakeVault(t, "2", tree, func(w http.ResponseWriter, r *http.Request) bool {
+		if requests.Add(1) > 1 {
+			return false
+		}
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":["rate limit quota exceeded"]}`)
+		return true
+	})
+
+	got, err := walk(client, "2", Options{})
+	if err != nil {
+		t.Fatalf("walk failed after a 429: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("visited %v, want all 3 secrets", got)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Fatalf("made %d requests, want 4 (one retried LIST and three directories)", n)
+	}
+}
+
+func TestWalkSecretsMaxSecrets(t *testing.T) {
+	client := fakeVault(t, "2", tree, nil)
+
+	got, err := walk(client, "2", Options{MaxSecrets: 2})
+	if !errors.Is(err, ErrSecretLimit) {
+		t.Fatalf("err = %v, want ErrSecretLimit", err)
+	}
+	if want := []string{"secret/app/db", "secret/app/nested/deep"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("visited %v before the limit, want %v", got, want)
+	}
+
+	if _, err := walk(client, "2", Options{MaxSecrets: 3}); err != nil {
+		t.Fatalf("walk of exactly MaxSecrets secrets failed: %v", err)
+	}
+}
+
+func TestWalkSecretsWaitsOnLimiter(t *testing.T) {
+	client := fakeVault(t, "2", tree, nil)
+
+	// the burst covers the first LIST only; the next one would have to wait an hour
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := WalkSecrets(ctx, client, "secret", "2", Options{Limiter: limiter}, func(string) error { return nil })
+	if err == nil {
+		t.Fatal("walk ignored the limiter")
+	}
+}
+
+func TestWalkSecretsUnsupportedVersion(t *testing.T) {
+	client := fakeVault(t, "2", tree, nil)
+
+	if _, err := walk(client, "3", Options{}); err == nil {
+		t.Fatal("walk of an unsupported KV version succeeded")
+	}
+}
+
+func TestWalkSecretsReauthenticates(t *testing.T) {
+	// the agent's sink holds the old token until the first LIST, then the new one
+	sink := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(sink, []byte("old-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var lists atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Vault-Token")
+		if r.URL.Path == "/v1/auth/token/lookup-self" || (token == "old-token" && lists.Load() > 0) {
+			if token != "new-token" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{}}`)
+			return
+		}
+		if lists.Add(1) == 1 {
+			if err := os.WriteFile(sink, []byte("new-token"), 0o600); err != nil {
+				t.Error(err)
+			}
+		}
+		keys := tree[strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"), "/")]
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_TARGET_ADDR", srv.URL)
+	t.Setenv("VAULT_TARGET_TOKEN", "")
+	t.Setenv("VAULT_TARGET_TOKEN_SINK", sink)
+	client, err := vaultclient.NewTargetClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := walk(client, "2", Options{})
+	if err != nil {
+		t.Fatalf("walk failed when the token expired midway: %v", err)
+	}
+	if want := []string{"secret/app/db", "secret/app/nested/deep", "secret/top"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("visited %v, want %v", got, want)
+	}
+}
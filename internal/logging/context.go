@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type attrsKey struct{}
+
+type bufferKey struct{}
+
+// WithAttrs returns a copy of ctx carrying attrs, which are added to every record logged
+// with it (slog.InfoContext and friends), after any attrs ctx already carries. Bulk
+// operations use it to tag each line with the worker and secret it belongs to.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(append(combined, existing...), attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// Buffered returns a copy of ctx whose records are held back instead of written, and a
+// flush function that writes them all at once, without lines from other goroutines in
+// between. Concurrent workers use it so each secret's messages stay contiguous. flush
+// must be called once the work logged with the context is done; records logged with it
+// afterwards are written immediately.
+func Buffered(ctx context.Context) (context.Context, func()) {
+	buf := &recordBuffer{}
+	return context.WithValue(ctx, bufferKey{}, buf), buf.flush
+}
+
+// bufferedRecord is a record held back by a Buffered context, with the handler that is
+// to write it.
+type bufferedRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+type recordBuffer struct {
+	mu      sync.Mutex
+	records []bufferedRecord
+	flushed bool
+}
+
+// add holds r back for handler and reports true, or reports false if the buffer was
+// already flushed.
+func (b *recordBuffer) add(ctx context.Context, handler slog.Handler, r slog.Record) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.flushed {
+		return false
+	}
+	b.records = append(b.records, bufferedRecord{ctx: ctx, handler: handler, record: r.Clone()})
+	return true
+}
+
+func (b *recordBuffer) flush() {
+	b.mu.Lock()
+	records := b.records
+	b.records, b.flushed = nil, true
+	b.mu.Unlock()
+	if len(records) == 0 {
+		return
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	for _, r := range records {
+		_ = r.handler.Handle(r.ctx, r.record)
+	}
+}
+
+// writeMu serializes writes to the underlying handler, so a flushed buffer's records
+// are never interleaved with other records.
+var writeMu sync.Mutex
+
+// contextHandler wraps the handler that formats and writes records, adding the attrs
+// carried by the record's context and holding records back for Buffered contexts. The
+// wrapped handler must be safe for concurrent use, as slog's built-in handlers are.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	if buf, ok := ctx.Value(bufferKey{}).(*recordBuffer); ok && buf.add(ctx, h.Handler, r) {
+		return nil
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
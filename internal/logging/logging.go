@@ -0,0 +1,149 @@
+/*
+Package logging configures the process-wide slog logger for the vaultx CLI.
+
+It installs a text handler on stderr whose level can be changed at runtime and which, unless
+explicitly disabled, redacts known secret values from every log record. Secret values are
+registered by the commands as they read or write them; any occurrence of a registered value in a
+log message or attribute (including error strings returned by the Vault client, which may embed
+request bodies) is replaced with "[REDACTED]".
+
+Redaction is on by default and can only be turned off with the global --unsafe-log-values flag.
+
+The handler is safe for concurrent use. Records logged with a context (slog.InfoContext and
+friends) also carry the attrs attached to it with WithAttrs, such as the worker and secret a
+line belongs to, and a context from Buffered holds its records back until they can be written
+together, so concurrent workers' output stays readable.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Redacted is the placeholder that replaces secret values in log output.
+const Redacted = "[REDACTED]"
+
+// minRedactLength is the shortest value that is redacted. Shorter values ("1", "true")
+// would otherwise mangle unrelated log text.
+const minRedactLength = 4
+
+var (
+	level = new(slog.LevelVar)
+
+	mu     sync.RWMutex
+	values = map[string]struct{}{}
+	unsafe bool
+)
+
+// Setup installs the vaultx handler as the default slog logger. When unsafeValues is
+// true registered secret values are logged verbatim.
+func Setup(unsafeValues bool) {
+	mu.Lock()
+	unsafe = unsafeValues
+	mu.Unlock()
+
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})
+	slog.SetDefault(slog.New(contextHandler{handler}))
+}
+
+// SetLevel changes the minimum level of records that are logged.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// RegisterSecretValues records every string value in data, recursing into nested maps
+// and slices, so that it is redacted from subsequent log output.
+func RegisterSecretValues(data map[string]interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, v := range data {
+		registerValue(v)
+	}
+}
+
+func registerValue(v interface{}) {
+	switch val := v.(type) {
+	case string:
+		if len(val) >= minRedactLength {
+			values[val] = struct{}{}
+		}
+	case map[string]interface{}:
+		for _, nested := range val {
+			registerValue(nested)
+		}
+	case []interface{}:
+		for _, nested := range val {
+			registerValue(nested)
+		}
+	}
+}
+
+// Redact returns s with every registered secret value replaced by Redacted. Occurrences
+// are found in s as given, and each run of text covered by one or more of them, even
+// overlapping ones such as "abcd" inside "abcdefgh", is replaced by a single Redacted,
+// so no part of a longer value is left visible.
+func Redact(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if unsafe || len(values) == 0 {
+		return s
+	}
+
+	var covered []bool
+	for v := range values {
+		for start := 0; ; {
+			i := strings.Index(s[start:], v)
+			if i < 0 {
+				break
+			}
+			if covered == nil {
+				covered = make([]bool, len(s))
+			}
+			for k := start + i; k < start+i+len(v); k++ {
+				covered[k] = true
+			}
+			start += i + 1
+		}
+	}
+	if covered == nil {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if !covered[i] {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteString(Redacted)
+		for i+1 < len(s) && covered[i+1] {
+			i++
+		}
+	}
+	return b.String()
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(Redact(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(Redact(v.String()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,33 @@
+package logging
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	t.Cleanup(func() { values = map[string]struct{}{} })
+	RegisterSecretValues(map[string]interface{}{
+		"short":   "abcd",
+		"long":    "abcdefgh",
+		"overlap": "ghij",
+		"tiny":    "xy",
+		"nested":  map[string]interface{}{"list": []interface{}{"s3cret-token"}},
+	})
+
+	tests := []struct {
+		in, want string
+	}{
+		{"no secrets here", "no secrets here"},
+		{"password=abcd", "password=" + Redacted},
+		{"password=abcdefgh", "password=" + Redacted},
+		{"abcdefghij!", Redacted + "!"},
+		{"abcd and abcdefgh", Redacted + " and " + Redacted},
+		{"token s3cret-token", "token " + Redacted},
+		{"xy is too short to redact", "xy is too short to redact"},
+	}
+	for _, tt := range tests {
+		for range 20 { // map iteration order must not matter
+			if got := Redact(tt.in); got != tt.want {
+				t.Fatalf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package vaultclient
+
+import "errors"
+
+// Sentinel errors for common failure modes. They are wrapped with context where they
+// occur, so match them with errors.Is rather than comparing error strings.
+var (
+	// ErrVaultClientMissing is returned when no Vault client was attached to the context.
+	ErrVaultClientMissing = errors.New("vault client not found in context")
+
+	// ErrMountNotFound is returned when a mount, or a mount matching a secret path, is not
+	// enabled on the Vault server.
+	ErrMountNotFound = errors.New("mount not found")
+
+	// ErrNotKVMount is returned when a mount exists but is not a KV secrets engine.
+	ErrNotKVMount = errors.New("mount is not a KV secrets engine")
+
+	// ErrMountConflict is returned when enabling a mount at a path that already holds a
+	// KV mount of a different version.
+	ErrMountConflict = errors.New("mount already exists with a different KV version")
+
+	// ErrMountUpgrading is returned when a KV mount is still being upgraded from v1 to v2,
+	// so its data can't yet be read or written reliably in either format.
+	ErrMountUpgrading = errors.New("KV mount is upgrading")
+
+	// ErrUnsupportedKVVersion is returned for a KV version other than "1" or "2".
+	ErrUnsupportedKVVersion = errors.New("unsupported KV version")
+
+	// ErrVaultSealed is returned when the Vault server is sealed and cannot serve requests.
+	ErrVaultSealed = errors.New("vault is sealed")
+
+	// ErrVaultNotInitialized is returned when the Vault server has not been initialized.
+	ErrVaultNotInitialized = errors.New("vault is not initialized")
+)
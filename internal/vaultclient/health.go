@@ -0,0 +1,114 @@
+package vaultclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	vault "github.com/hashicorp/vault-client-go"
+)
+
+// MinTokenTTL is the remaining token lifetime below which callers warn that a long
+// operation may not finish before the token expires.
+const MinTokenTTL = 10 * time.Minute
+
+// Health is what sys/health reported about a usable Vault instance.
+type Health struct {
+	// Standby is set for standby and performance standby nodes, which forward or serve
+	// requests like the active node.
+	Standby bool
+	// Version is the Vault version.
+	Version string
+}
+
+// CheckHealth queries sys/health, which answers without a token, and returns an error if
+// the instance behind client can't serve requests: it is unreachable, not initialized
+// (wrapping ErrVaultNotInitialized) or sealed (wrapping ErrVaultSealed). name identifies
+// the instance ("source" or "target") in the message.
+//
+// sys/health reports standby, sealed and uninitialized nodes with 429, 473, 503 and 501
+// statuses, but the client parses its body whatever the status, so the state is read
+// from the body alone.
+func CheckHealth(ctx context.Context, client *vault.Client, name string) (Health, error) {
+	addr := client.Configuration().Address
+
+	resp, err := client.System.ReadHealthStatus(ctx)
+	if err != nil {
+		return Health{}, fmt.Errorf("%s Vault at %s is unreachable: %w", name, addr, err)
+	}
+
+	initialized, ok := resp.Data["initialized"].(bool)
+	switch {
+	case !ok:
+		// e.g. a proxy's error page instead of Vault's answer
+		return Health{}, fmt.Errorf("%s Vault at %s is unreachable: sys/health returned no status", name, addr)
+	case !initialized:
+		return Health{}, fmt.Errorf("%w: %s Vault at %s", ErrVaultNotInitialized, name, addr)
+	}
+	if sealed, _ := resp.Data["sealed"].(bool); sealed {
+		return Health{}, fmt.Errorf("%w: %s Vault at %s; unseal it before retrying", ErrVaultSealed, name, addr)
+	}
+
+	var health Health
+	standby, _ := resp.Data["standby"].(bool)
+	perfStandby, _ := resp.Data["performance_standby"].(bool)
+	health.Standby = standby || perfStandby
+	health.Version, _ = resp.Data["version"].(string)
+	return health, nil
+}
+
+// TokenInfo is what a lookup of the client's own token reported.
+type TokenInfo struct {
+	// TTL is the token's remaining lifetime; 0 means it never expires (e.g. root tokens).
+	TTL      time.Duration
+	Policies []string
+}
+
+// LookupToken looks up the client's own token and returns an error if it is invalid or
+// expired. name identifies the instance ("source" or "target") in the message.
+func LookupToken(ctx context.Context, client *vault.Client, name string) (TokenInfo, error) {
+	resp, err := client.Auth.TokenLookUpSelf(ctx)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("%s token is invalid or expired: %w", name, err)
+	}
+
+	var info TokenInfo
+	if ttl, ok := resp.Data["ttl"].(json.Number); ok {
+		if seconds, err := ttl.Int64(); err == nil && seconds > 0 {
+			info.TTL = time.Duration(seconds) * time.Second
+		}
+	}
+	raw, _ := resp.Data["policies"].([]interface{})
+	for _, p := range raw {
+		if s, ok := p.(string); ok {
+			info.Policies = append(info.Policies, s)
+		}
+	}
+	return info, nil
+}
+
+// CheckSealed returns an error wrapping ErrVaultSealed if the Vault instance behind
+// client is sealed, so commands can abort with one clear message instead of failing on
+// every request. name identifies the instance ("source" or "target") in the message.
+//
+// sys/seal-status answers even while Vault is sealed. If it cannot be queried for any
+// other reason (for example through a proxy that doesn't forward it), the check is
+// skipped and the operation's own requests report the problem.
+func CheckSealed(ctx context.Context, client *vault.Client, name string) error {
+	resp, err := client.System.SealStatus(ctx)
+	if err != nil {
+		if vault.IsErrorStatus(err, http.StatusServiceUnavailable) {
+			return fmt.Errorf("%w: %s Vault at %s; unseal it before retrying", ErrVaultSealed, name, client.Configuration().Address)
+		}
+		slog.Debug("unable to query seal status", "vault", name, "error", err)
+		return nil
+	}
+
+	if resp.Data.Sealed {
+		return fmt.Errorf("%w: %s Vault at %s; unseal it before retrying", ErrVaultSealed, name, client.Configuration().Address)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package vaultclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault-client-go"
+)
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		want        Health
+		wantErr     error
+		unreachable bool
+	}{
+		{
+			name:   "active",
+			status: http.StatusOK,
+			body:   `{"initialized":true,"sealed":false,"standby":false,"version":"1.15.0"}`,
+			want:   Health{Version: "1.15.0"},
+		},
+		{
+			name:   "standby",
+			status: http.StatusTooManyRequests,
+			body:   `{"initialized":true,"sealed":false,"standby":true,"version":"1.15.0"}`,
+			want:   Health{Standby: true, Version: "1.15.0"},
+		},
+		{
+			name:   "performance standby",
+			status: 473,
+			body:   `{"initialized":true,"sealed":false,"standby":false,"performance_standby":true,"version":"1.15.0"}`,
+			want:   Health{Standby: true, Version: "1.15.0"},
+		},
+		{
+			name:    "sealed",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"initialized":true,"sealed":true}`,
+			wantErr: ErrVaultSealed,
+		},
+		{
+			name:    "not initialized",
+			status:  http.StatusNotImplemented,
+			body:    `{"initialized":false,"sealed":true}`,
+			wantErr: ErrVaultNotInitialized,
+		},
+		{
+			name:        "server error",
+			status:      http.StatusInternalServerError,
+			body:        `{"errors":["boom"]}`,
+			unreachable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+			client, err := vault.New(vault.WithAddress(srv.URL), vault.WithRetryConfiguration(vault.RetryConfiguration{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := CheckHealth(context.Background(), client, "source")
+			switch {
+			case tt.unreachable:
+				if err == nil || errors.Is(err, ErrVaultSealed) || errors.Is(err, ErrVaultNotInitialized) {
+					t.Fatalf("err = %v, want the instance reported unreachable", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case got != tt.want:
+				t.Fatalf("health = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
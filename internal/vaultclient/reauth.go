@@ -0,0 +1,191 @@
+package vaultclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	vault "github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+)
+
+// defaultK8sTokenFile is where Kubernetes mounts a pod's service account token.
+const defaultK8sTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// loginFunc logs in to Vault with long-lived auth material and returns a new token.
+type loginFunc func(ctx context.Context, client *vault.Client) (string, error)
+
+// reauth tracks how a client can log in again, and how many times it has, so that
+// workers failing on the same expired token trigger a single login.
+type reauth struct {
+	mu         sync.Mutex
+	login      loginFunc
+	generation int
+}
+
+var (
+	reauthMu      sync.Mutex
+	reauthClients = map[*vault.Client]*reauth{}
+)
+
+// registerReauth records how client can log in again, using the token sink, AppRole or
+// Kubernetes auth material in environment variables starting with envPrefix ("VAULT_"
+// for the source, "VAULT_TARGET_" for the target):
+//
+//   - <prefix>TOKEN_SINK re-reads the token from a Vault agent's sink file, which the
+//     agent rewrites whenever it renews or replaces the token
+//   - <prefix>ROLE_ID and <prefix>SECRET_ID log in with AppRole, at the mount named by
+//     <prefix>APPROLE_MOUNT (default "approle")
+//   - <prefix>K8S_ROLE logs in with the Kubernetes auth method, at the mount named by
+//     <prefix>K8S_MOUNT (default "kubernetes"), using the service account token in
+//     <prefix>K8S_TOKEN_FILE (default the pod's mounted token)
+//
+// They are tried in that order. Without any, the client cannot re-authenticate and
+// expired tokens stay fatal.
+func registerReauth(client *vault.Client, envPrefix string) {
+	login := loginFromEnv(envPrefix)
+	if login == nil {
+		return
+	}
+
+	reauthMu.Lock()
+	defer reauthMu.Unlock()
+	reauthClients[client] = &reauth{login: login}
+}
+
+func loginFromEnv(prefix string) loginFunc {
+	env := func(name, fallback string) string {
+		if v := os.Getenv(prefix + name); v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	// a Vault agent keeps the sink file current, so logging in is just reading it again
+	if sink := env("TOKEN_SINK", ""); sink != "" {
+		return func(ctx context.Context, client *vault.Client) (string, error) {
+			return readTokenSink(sink)
+		}
+	}
+
+	if roleID, secretID := env("ROLE_ID", ""), env("SECRET_ID", ""); roleID != "" && secretID != "" {
+		mount := env("APPROLE_MOUNT", "approle")
+		return func(ctx context.Context, client *vault.Client) (string, error) {
+			resp, err := client.Auth.AppRoleLogin(ctx, schema.AppRoleLoginRequest{RoleId: roleID, SecretId: secretID}, vault.WithMountPath(mount))
+			if err != nil {
+				return "", fmt.Errorf("approle login failed: %w", err)
+			}
+			return clientToken(resp), nil
+		}
+	}
+
+	if role := env("K8S_ROLE", ""); role != "" {
+		mount := env("K8S_MOUNT", "kubernetes")
+		tokenFile := env("K8S_TOKEN_FILE", defaultK8sTokenFile)
+		return func(ctx context.Context, client *vault.Client) (string, error) {
+			jwt, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return "", fmt.Errorf("failed to read service account token: %w", err)
+			}
+			resp, err := client.Auth.KubernetesLogin(ctx, schema.KubernetesLoginRequest{Role: role, Jwt: strings.TrimSpace(string(jwt))}, vault.WithMountPath(mount))
+			if err != nil {
+				return "", fmt.Errorf("kubernetes login failed: %w", err)
+			}
+			return clientToken(resp), nil
+		}
+	}
+
+	return nil
+}
+
+// readTokenSink returns the token in a Vault agent sink file, failing if it is empty.
+func readTokenSink(file string) (string, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token sink: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", fmt.Errorf("token sink %s is empty", file)
+	}
+	return token, nil
+}
+
+// clientToken returns the token issued by a login response, or "" if there is none.
+func clientToken(resp *vault.Response[map[string]interface{}]) string {
+	if resp.Auth == nil {
+		return ""
+	}
+	return resp.Auth.ClientToken
+}
+
+// AuthGeneration returns how many times client has re-authenticated. Callers record it
+// before a request and pass it to Reauthenticate if the request is denied.
+func AuthGeneration(client *vault.Client) int {
+	r := lookupReauth(client)
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// Reauthenticate handles err, returned by a request client made at auth generation
+// generation, and reports whether the request should be retried with a fresh token.
+//
+// Only a 403 caused by the token itself qualifies: if the token still passes a
+// lookup-self, the 403 is a genuine permission denial on that path and false is
+// returned. If the client has no re-auth material, false is returned as well. When
+// several requests fail on the same expired token, only the first logs in; the rest see
+// the newer generation and simply retry.
+func Reauthenticate(ctx context.Context, client *vault.Client, generation int, err error) bool {
+	if !vault.IsErrorStatus(err, http.StatusForbidden) {
+		return false
+	}
+	r := lookupReauth(client)
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation != generation {
+		return true
+	}
+	if _, lookupErr := client.Auth.TokenLookUpSelf(ctx); lookupErr == nil || !vault.IsErrorStatus(lookupErr, http.StatusForbidden) {
+		return false
+	}
+
+	slog.Warn("vault token expired or revoked, re-authenticating")
+	// log in without the expired token, which Vault would otherwise reject
+	loginClient := client.Clone()
+	loginClient.ClearToken()
+	token, loginErr := r.login(ctx, loginClient)
+	if loginErr == nil && token == "" {
+		loginErr = errors.New("login returned no token")
+	}
+	if loginErr != nil {
+		slog.Error("failed to re-authenticate", "error", loginErr)
+		return false
+	}
+	if setErr := client.SetToken(token); setErr != nil {
+		slog.Error("failed to set new vault token", "error", setErr)
+		return false
+	}
+
+	r.generation++
+	return true
+}
+
+func lookupReauth(client *vault.Client) *reauth {
+	reauthMu.Lock()
+	defer reauthMu.Unlock()
+	return reauthClients[client]
+}
@@ -0,0 +1,42 @@
+package vaultclient
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	vault "github.com/hashicorp/vault-client-go"
+)
+
+// RequestIDHeader carries the vaultx operation ID on every request. Vault only records it
+// in the audit log once the header is enabled with
+//
+//	vault write sys/config/auditing/request-headers/x-vaultx-request-id hmac=false
+const RequestIDHeader = "X-Vaultx-Request-Id"
+
+var requestID string
+
+// SetRequestID sets the operation ID sent by every client created afterwards. An empty
+// id sends no header.
+func SetRequestID(id string) {
+	requestID = id
+}
+
+// NewRequestID returns a random (version 4) UUID to identify one vaultx invocation.
+func NewRequestID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// applyRequestID adds the operation ID header to client's requests, if one is set.
+func applyRequestID(client *vault.Client) error {
+	if requestID == "" {
+		return nil
+	}
+	return client.SetCustomHeaders(http.Header{RequestIDHeader: {requestID}})
+}
@@ -0,0 +1,109 @@
+package vaultclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"golang.org/x/time/rate"
+)
+
+const (
+	maxRateLimitRetries = 5
+	initialRetryBackoff = time.Second
+	maxRetryBackoff     = 30 * time.Second
+	maxTimeoutRetries   = 3
+)
+
+// WithRetry runs op after waiting on limiter (if any), retrying it when Vault responds with
+// 429 Too Many Requests, or with 403 Forbidden because client's token expired and could
+// be renewed (see Reauthenticate).
+//
+// op is handed the context and a request option to pass to the Vault client call, which
+// must be made with client. The option records the response's Retry-After header. When
+// the header is present its delay is honored; otherwise the wait doubles on each attempt,
+// capped at maxRetryBackoff. Any other error, a 429 after maxRateLimitRetries attempts,
+// or a 403 that re-authenticating doesn't fix, is returned as-is.
+//
+// If ctx carries a request timeout (see WithRequestTimeout), each attempt's context
+// expires that long after the limiter let it through, so time spent waiting for a turn
+// doesn't count against it. An attempt that runs out of time is retried, up to
+// maxTimeoutRetries times.
+func WithRetry(ctx context.Context, client *vault.Client, limiter *rate.Limiter, op func(context.Context, vault.RequestOption) error) error {
+	backoff := initialRetryBackoff
+	reauthenticated := false
+	timeout, _ := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	timeouts := 0
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		var retryAfter string
+		record := vault.WithResponseCallbacks(func(_ *http.Request, resp *http.Response) {
+			retryAfter = resp.Header.Get("Retry-After")
+		})
+
+		generation := AuthGeneration(client)
+		err := runWithTimeout(ctx, timeout, func(opCtx context.Context) error {
+			return op(opCtx, record)
+		})
+		if err == nil {
+			return nil
+		}
+		if timeout > 0 && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil && timeouts < maxTimeoutRetries {
+			timeouts++
+			slog.WarnContext(ctx, "vault request timed out, retrying", "timeout", timeout, "attempt", timeouts)
+			continue
+		}
+		if !reauthenticated && Reauthenticate(ctx, client, generation, err) {
+			reauthenticated = true
+			continue
+		}
+		if !vault.IsErrorStatus(err, http.StatusTooManyRequests) || attempt > maxRateLimitRetries {
+			return err
+		}
+
+		wait := backoff
+		if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		slog.WarnContext(ctx, "vault rate limit reached, backing off", "wait", wait, "attempt", attempt)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// requestTimeoutKey is the context key under which WithRequestTimeout stores the timeout.
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a context under which every request made through WithRetry
+// is bounded by timeout and retried when it runs out of time. A non-positive timeout
+// lifts any bound set further up.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// runWithTimeout runs op with a context derived from ctx that expires after timeout, or
+// with ctx itself if timeout is not positive.
+func runWithTimeout(ctx context.Context, timeout time.Duration, op func(context.Context) error) error {
+	if timeout <= 0 {
+		return op(ctx)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return op(opCtx)
+}
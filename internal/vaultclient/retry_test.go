@@ -0,0 +1,98 @@
+package vaultclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"golang.org/x/time/rate"
+)
+
+// slowVault returns a client for a server that answers each request after the delay
+// delays returns for it, and counts the requests.
+func slowVault(t *testing.T, delay func(request int32) time.Duration) (*vault.Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay(requests.Add(1))):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"data":{"keys":["a"]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := vault.New(vault.WithAddress(srv.URL), vault.WithRetryConfiguration(vault.RetryConfiguration{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &requests
+}
+
+func list(client *vault.Client) func(context.Context, vault.RequestOption) error {
+	return func(ctx context.Context, opt vault.RequestOption) error {
+		_, err := client.Secrets.KvV2List(ctx, "", vault.WithMountPath("secret"), opt)
+		return err
+	}
+}
+
+func TestWithRetryRetriesTimedOutRequests(t *testing.T) {
+	client, requests := slowVault(t, func(request int32) time.Duration {
+		if request == 1 {
+			return time.Second
+		}
+		return 0
+	})
+
+	ctx := WithRequestTimeout(context.Background(), 50*time.Millisecond)
+	if err := WithRetry(ctx, client, nil, list(client)); err != nil {
+		t.Fatalf("request failed after a timeout: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("made %d requests, want 2", n)
+	}
+}
+
+func TestWithRetryGivesUpAfterRepeatedTimeouts(t *testing.T) {
+	client, requests := slowVault(t, func(int32) time.Duration { return time.Second })
+
+	ctx := WithRequestTimeout(context.Background(), 20*time.Millisecond)
+	if err := WithRetry(ctx, client, nil, list(client)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if n := requests.Load(); n != maxTimeoutRetries+1 {
+		t.Fatalf("made %d requests, want %d", n, maxTimeoutRetries+1)
+	}
+}
+
+func TestWithRetryTimeoutExcludesLimiterWait(t *testing.T) {
+	client, requests := slowVault(t, func(int32) time.Duration { return 0 })
+
+	// the second request waits about 200ms for the limiter, four times the timeout
+	limiter := rate.NewLimiter(rate.Every(200*time.Millisecond), 1)
+	ctx := WithRequestTimeout(context.Background(), 50*time.Millisecond)
+	for range 2 {
+		if err := WithRetry(ctx, client, limiter, list(client)); err != nil {
+			t.Fatalf("request failed after waiting on the limiter: %v", err)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("made %d requests, want 2", n)
+	}
+}
+
+func TestWithRequestTimeoutZeroLiftsBound(t *testing.T) {
+	client, _ := slowVault(t, func(int32) time.Duration { return 100 * time.Millisecond })
+
+	ctx := WithRequestTimeout(WithRequestTimeout(context.Background(), 20*time.Millisecond), 0)
+	if err := WithRetry(ctx, client, nil, list(client)); err != nil {
+		t.Fatalf("unbounded request failed: %v", err)
+	}
+}
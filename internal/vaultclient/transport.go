@@ -0,0 +1,49 @@
+package vaultclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	vault "github.com/hashicorp/vault-client-go"
+)
+
+// TransportOptions tunes the HTTP connections of each Vault client. Every client owns a
+// single pooled transport, shared by all goroutines using it, so concurrent copies reuse
+// connections instead of opening a socket per request.
+type TransportOptions struct {
+	// MaxIdleConns is the number of idle connections kept open to the Vault server; 0
+	// keeps the client default. Set it to at least the copy concurrency.
+	MaxIdleConns int
+	// ConnectTimeout bounds how long establishing a connection may take; 0 keeps the
+	// client default of 30s.
+	ConnectTimeout time.Duration
+}
+
+var transportOptions TransportOptions
+
+// SetTransportOptions sets the transport tuning used by every client created afterwards.
+func SetTransportOptions(opts TransportOptions) {
+	transportOptions = opts
+}
+
+// httpClient returns vault-client-go's default HTTP client with transportOptions applied.
+func httpClient() *http.Client {
+	client := vault.DefaultConfiguration().HTTPClient
+	transport := client.Transport.(*http.Transport)
+
+	if n := transportOptions.MaxIdleConns; n > 0 {
+		// each client talks to a single Vault server, so the per-host limit is the one
+		// that matters
+		transport.MaxIdleConns = n
+		transport.MaxIdleConnsPerHost = n
+	}
+	if timeout := transportOptions.ConnectTimeout; timeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+
+	return client
+}
@@ -3,12 +3,27 @@ Package vaultclient provides a simplified Vault client initialization and access
 
 It handles:
   - Initialization of a HashiCorp Vault client using environment variables (VAULT_ADDR, VAULT_TOKEN)
+  - Reading the token from a Vault agent sink file (VAULT_TOKEN_SINK) when VAULT_TOKEN is unset
+  - Falling back to the selected environment in the vaultx config file when they are unset,
+    or overriding them with the environment chosen by --context
+  - Falling back to the token saved by "vault login" in ~/.vault-token
+  - Tuning each client's pooled HTTP transport (see TransportOptions)
+  - Tagging every request with the invocation's operation ID (see RequestIDHeader)
+  - Re-reading the agent sink, or re-authenticating with AppRole or Kubernetes credentials,
+    when a token expires mid-run
+    (see Reauthenticate)
   - Attaching the client to a context for easy retrieval throughout the application
   - Graceful logging when configuration is missing or the client is not found
 
 Environment Variables:
-  VAULT_ADDR   - The address of the Vault server (e.g., https://vault.example.com)
-  VAULT_TOKEN  - The Vault token used for authentication
+  VAULT_ADDR          - The address of the Vault server (e.g., https://vault.example.com)
+  VAULT_TOKEN         - The Vault token used for authentication
+  VAULT_TARGET_ADDR   - The address of the target Vault server for copy operations
+  VAULT_TARGET_TOKEN  - The Vault token used for the target Vault server
+  VAULT_TOKEN_SINK, VAULT_TARGET_TOKEN_SINK
+                      - Vault agent sink files to read the token from when the token is unset
+  VAULT_ROLE_ID, VAULT_SECRET_ID, VAULT_K8S_ROLE (and VAULT_TARGET_ equivalents)
+                      - Optional credentials to log in again with if the token expires
 
 This package is intended to centralize Vault client setup and promote safe and consistent access
 to the client across subcommands.
@@ -18,10 +33,15 @@ package vaultclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 
 	vault "github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/config"
 )
 
 type ctxKey string
@@ -37,21 +57,159 @@ func GetVaultClient(ctx context.Context) *vault.Client {
 	return client
 }
 
-func InitVaultContext() (context.Context, error) {
+// InitVaultContext creates a Vault client and returns a copy of ctx carrying it.
+//
+// An environment chosen with --context comes first. Otherwise VAULT_ADDR and VAULT_TOKEN
+// take precedence, then a token in the VAULT_TOKEN_SINK file; any that are unset are
+// filled in from the environment selected in the config stored in ctx, and finally from
+// ~/.vault-token.
+// It fails with ErrVaultSealed if the Vault instance is sealed.
+func InitVaultContext(ctx context.Context) (context.Context, error) {
+	client, err := NewSourceClient(config.FromContext(ctx))
+	if err != nil {
+		if errors.Is(err, errMissingCredentials) {
+			slog.Error("VAULT_ADDR and VAULT_TOKEN environment variables must be set, or provided by a config environment or \"vault login\".")
+			os.Exit(1)
+		}
+		slog.Error("Failed to initialize vault client", "error", err)
+		return nil, err
+	}
+
+	if err := CheckSealed(ctx, client, "source"); err != nil {
+		slog.Error("Vault is sealed", "error", err)
+		return nil, err
+	}
+
+	return context.WithValue(ctx, vaultClientKey, client), nil
+}
+
+var errMissingCredentials = errors.New("VAULT_ADDR and VAULT_TOKEN must be set, or provided by a config environment")
+
+// NewSourceClient creates a client for the Vault instance that commands operate on, from
+// the environment chosen with --context (cfg.Context) if any, otherwise from VAULT_ADDR
+// and VAULT_TOKEN (or the VAULT_TOKEN_SINK file) or, where those are unset, the
+// environment selected in cfg.
+// As a last resort the token is read from ~/.vault-token, as the official Vault CLI does.
+//
+// Unlike InitVaultContext it never exits, so it is safe to call from shell completion.
+func NewSourceClient(cfg *config.Config) (*vault.Client, error) {
 	addr := os.Getenv("VAULT_ADDR")
-	token := os.Getenv("VAULT_TOKEN")
+	token, err := envToken("VAULT_")
+	if err != nil {
+		return nil, err
+	}
+
+	env, ok, err := cfg.CurrentEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config environment: %w", err)
+	}
+	if ok {
+		// an environment picked with --context is as explicit as any flag, so it
+		// overrides the variables; the persisted selection only fills in for them
+		explicit := cfg.Context != ""
+		if (addr == "" || explicit) && env.Addr != "" {
+			addr = env.Addr
+		}
+		if token == "" || explicit {
+			configToken, err := env.ResolveToken()
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve token from config environment: %w", err)
+			}
+			if configToken != "" {
+				token = configToken
+			}
+		}
+	}
+
+	if token == "" {
+		token, err = vaultTokenFile()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token from ~/%s: %w", vaultTokenFileName, err)
+		}
+	}
 
 	if addr == "" || token == "" {
-		slog.Error("VAULT_ADDR and VAULT_TOKEN environment variables must be set.")
-		os.Exit(1)
+		return nil, errMissingCredentials
 	}
 
-	client, err := vault.New(vault.WithEnvironment())
+	client, err := vault.New(vault.WithEnvironment(), vault.WithAddress(addr), vault.WithHTTPClient(httpClient()))
 	if err != nil {
-		slog.Error("Failed to initialize vault client", "error", err)
 		return nil, err
 	}
 
-	ctx := context.WithValue(context.Background(), vaultClientKey, client)
-	return ctx, nil
+	if err := client.SetToken(token); err != nil {
+		return nil, fmt.Errorf("failed to set vault token: %w", err)
+	}
+
+	if err := applyRequestID(client); err != nil {
+		return nil, fmt.Errorf("failed to set request ID header: %w", err)
+	}
+
+	registerReauth(client, "VAULT_")
+
+	return client, nil
+}
+
+// envToken returns <prefix>TOKEN or, if it is unset, the token in the Vault agent sink
+// file named by <prefix>TOKEN_SINK. It returns "" if neither is set.
+func envToken(prefix string) (string, error) {
+	if token := os.Getenv(prefix + "TOKEN"); token != "" {
+		return token, nil
+	}
+	if sink := os.Getenv(prefix + "TOKEN_SINK"); sink != "" {
+		return readTokenSink(sink)
+	}
+	return "", nil
+}
+
+// vaultTokenFileName is where "vault login" stores the token, relative to the home directory.
+const vaultTokenFileName = ".vault-token"
+
+// vaultTokenFile returns the token saved by the official Vault CLI's "vault login", or
+// "" if there is none.
+func vaultTokenFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", nil
+	}
+
+	raw, err := os.ReadFile(filepath.Join(home, vaultTokenFileName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// NewTargetClient creates a client for the target Vault instance of a copy operation
+// from VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN, or the VAULT_TARGET_TOKEN_SINK file.
+func NewTargetClient() (*vault.Client, error) {
+	targetAddr := os.Getenv("VAULT_TARGET_ADDR")
+	targetToken, err := envToken("VAULT_TARGET_")
+	if err != nil {
+		return nil, err
+	}
+
+	if targetAddr == "" || targetToken == "" {
+		return nil, errors.New("VAULT_TARGET_ADDR and VAULT_TARGET_TOKEN (or VAULT_TARGET_TOKEN_SINK) environment variables are required")
+	}
+
+	client, err := vault.New(vault.WithAddress(targetAddr), vault.WithHTTPClient(httpClient()))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.SetToken(targetToken); err != nil {
+		return nil, err
+	}
+
+	if err := applyRequestID(client); err != nil {
+		return nil, fmt.Errorf("failed to set request ID header: %w", err)
+	}
+
+	registerReauth(client, "VAULT_TARGET_")
+
+	return client, nil
 }
@@ -0,0 +1,92 @@
+package vaultclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/razahuss02/vaultx/internal/config"
+)
+
+// recordingVault starts a server that stores the name it was given and the token of
+// each request in *seen, and returns its address.
+func recordingVault(t *testing.T, name string, seen *string) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*seen = name + " " + r.Header.Get("X-Vault-Token")
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestNewSourceClientEnvironmentPrecedence(t *testing.T) {
+	var seen string
+	cfg := config.Config{
+		Environment: "dev",
+		Environments: map[string]config.Environment{
+			"dev":  {Addr: recordingVault(t, "dev", &seen), Token: "dev-token"},
+			"prod": {Addr: recordingVault(t, "prod", &seen), Token: "prod-token"},
+		},
+	}
+	shellAddr := recordingVault(t, "shell", &seen)
+
+	tests := []struct {
+		name    string
+		context string
+		addr    string
+		token   string
+		want    string
+	}{
+		{
+			name: "persisted selection fills in unset variables",
+			want: "dev dev-token",
+		},
+		{
+			name:  "variables override the persisted selection",
+			addr:  shellAddr,
+			token: "shell-token",
+			want:  "shell shell-token",
+		},
+		{
+			name:    "--context overrides the variables",
+			context: "prod",
+			addr:    shellAddr,
+			token:   "shell-token",
+			want:    "prod prod-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_ADDR", tt.addr)
+			t.Setenv("VAULT_TOKEN", tt.token)
+			t.Setenv("VAULT_TOKEN_SINK", "")
+			t.Setenv("HOME", t.TempDir())
+
+			cfg := cfg
+			cfg.Context = tt.context
+			client, err := NewSourceClient(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen = ""
+			if _, err := client.Read(context.Background(), "sys/ping"); err != nil {
+				t.Fatal(err)
+			}
+			if seen != tt.want {
+				t.Errorf("request went to %q, want %q", seen, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSourceClientUnknownContext(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "https://vault.shell.example.com")
+	t.Setenv("VAULT_TOKEN", "shell-token")
+
+	if _, err := NewSourceClient(&config.Config{Context: "missing"}); err == nil {
+		t.Fatal("NewSourceClient accepted a --context that isn't defined in the config")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Package identity exports Vault identity entities and groups.
+
+Entities and groups are easy to forget in a migration because they live outside any
+secrets engine. Export reads every entity and group through the identity endpoints and
+returns them in a serializable form. Group memberships are reported by name as well as by
+ID, since IDs are generated per Vault instance and do not survive a migration.
+*/
+
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+)
+
+// Snapshot is the exported content of the identity store.
+type Snapshot struct {
+	Entities []Entity `json:"entities"`
+	Groups   []Group  `json:"groups"`
+}
+
+// Entity is an identity entity and the auth method aliases that map to it.
+type Entity struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Disabled bool              `json:"disabled"`
+	Policies []string          `json:"policies"`
+	Metadata map[string]string `json:"metadata"`
+	Aliases  []Alias           `json:"aliases"`
+}
+
+// Alias ties an entity or group to a name within an auth method mount.
+type Alias struct {
+	Name          string            `json:"name"`
+	MountAccessor string            `json:"mount_accessor"`
+	MountPath     string            `json:"mount_path"`
+	MountType     string            `json:"mount_type"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// Group is an identity group and its memberships.
+type Group struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Type            string            `json:"type"`
+	Policies        []string          `json:"policies"`
+	Metadata        map[string]string `json:"metadata"`
+	Alias           *Alias            `json:"alias,omitempty"`
+	MemberEntityIDs []string          `json:"member_entity_ids"`
+	MemberGroupIDs  []string          `json:"member_group_ids"`
+
+	// MemberEntities and MemberGroups hold the names of the members, resolved from the
+	// IDs above.
+	MemberEntities []string `json:"member_entities"`
+	MemberGroups   []string `json:"member_groups"`
+}
+
+// Export reads every identity entity and group visible to client's token.
+func Export(ctx context.Context, client *vault.Client) (*Snapshot, error) {
+	entityIDs, err := listIDs(ctx, client.Identity.EntityListById)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	out := &Snapshot{}
+	entityNames := make(map[string]string, len(entityIDs))
+	for _, id := range entityIDs {
+		resp, err := client.Identity.EntityReadById(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read entity %q: %w", id, err)
+		}
+
+		var entity Entity
+		if err := decode(resp.Data, &entity); err != nil {
+			return nil, fmt.Errorf("unexpected entity %q: %w", id, err)
+		}
+		entityNames[entity.ID] = entity.Name
+		out.Entities = append(out.Entities, entity)
+	}
+
+	groupIDs, err := listIDs(ctx, client.Identity.GroupListById)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	groupNames := make(map[string]string, len(groupIDs))
+	for _, id := range groupIDs {
+		resp, err := client.Identity.GroupReadById(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read group %q: %w", id, err)
+		}
+
+		var group Group
+		if err := decode(resp.Data, &group); err != nil {
+			return nil, fmt.Errorf("unexpected group %q: %w", id, err)
+		}
+		groupNames[group.ID] = group.Name
+		out.Groups = append(out.Groups, group)
+	}
+
+	for i := range out.Groups {
+		out.Groups[i].MemberEntities = resolveNames(out.Groups[i].MemberEntityIDs, entityNames)
+		out.Groups[i].MemberGroups = resolveNames(out.Groups[i].MemberGroupIDs, groupNames)
+	}
+
+	sort.Slice(out.Entities, func(i, j int) bool { return out.Entities[i].Name < out.Entities[j].Name })
+	sort.Slice(out.Groups, func(i, j int) bool { return out.Groups[i].Name < out.Groups[j].Name })
+
+	return out, nil
+}
+
+// listIDs calls one of the identity LIST-by-ID endpoints. Vault answers 404 when there
+// is nothing to list, which is reported as an empty list.
+func listIDs(ctx context.Context, list func(context.Context, ...vault.RequestOption) (*vault.Response[schema.StandardListResponse], error)) ([]string, error) {
+	resp, err := list(ctx)
+	if err != nil {
+		if vault.IsErrorStatus(err, http.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return resp.Data.Keys, nil
+}
+
+// decode converts a generic response body into v by round-tripping it through JSON.
+func decode(data map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// resolveNames maps ids to names, keeping the ID for any member that could not be
+// resolved (for example one outside the token's namespace).
+func resolveNames(ids []string, names map[string]string) []string {
+	resolved := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			resolved = append(resolved, name)
+		} else {
+			resolved = append(resolved, id)
+		}
+	}
+	sort.Strings(resolved)
+	return resolved
+}
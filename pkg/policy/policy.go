@@ -0,0 +1,119 @@
+/*
+Package policy copies ACL policies between Vault instances.
+
+Policies are a core part of any Vault migration. Copy lists the ACL policies on a source
+instance and writes each one, unchanged, to a target instance, optionally filtered by
+glob patterns on the policy name. The built-in "root" policy cannot be modified and is
+never copied.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+)
+
+// CopyOptions controls which policies Copy writes.
+type CopyOptions struct {
+	// Include limits the copy to policies whose name matches one of these glob patterns
+	// (path.Match syntax). Empty includes every policy.
+	Include []string
+	// Exclude skips policies whose name matches one of these glob patterns. It takes
+	// precedence over Include.
+	Exclude []string
+	// DryRun reports what would be copied without writing to the target.
+	DryRun bool
+}
+
+// CopyResult reports what Copy did with each policy on the source.
+type CopyResult struct {
+	Copied  []string // policies written to the target, or that would be on a dry run
+	Skipped []string // policies filtered out
+	Failed  []string // policies that could not be read or written
+}
+
+// Copy copies the ACL policies selected by opts from sourceClient to targetClient,
+// overwriting any policy of the same name on the target.
+//
+// Policies that fail to copy are logged and reported in the result's Failed list; the
+// returned error is reserved for problems that stop the whole run.
+func Copy(ctx context.Context, sourceClient, targetClient *vault.Client, opts CopyOptions) (*CopyResult, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid policy pattern %q: %w", pattern, err)
+		}
+	}
+
+	resp, err := sourceClient.System.PoliciesListAclPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source policies: %w", err)
+	}
+
+	names := resp.Data.Keys
+	if len(names) == 0 {
+		names = resp.Data.Policies
+	}
+	sort.Strings(names)
+
+	result := &CopyResult{}
+	for _, name := range names {
+		if name == "root" || !selected(name, opts) {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+
+		policy, err := sourceClient.System.PoliciesReadAclPolicy(ctx, name)
+		if err != nil {
+			slog.Error("failed to read policy", "policy", name, "error", err)
+			result.Failed = append(result.Failed, name)
+			continue
+		}
+
+		if opts.DryRun {
+			slog.Info("would copy policy", "policy", name)
+			result.Copied = append(result.Copied, name)
+			continue
+		}
+
+		_, err = targetClient.System.PoliciesWriteAclPolicy(ctx, name, schema.PoliciesWriteAclPolicyRequest{
+			Policy: policy.Data.Policy,
+		})
+		if err != nil {
+			slog.Error("failed to write policy to target", "policy", name, "error", err)
+			result.Failed = append(result.Failed, name)
+			continue
+		}
+
+		slog.Info("copied policy", "policy", name)
+		result.Copied = append(result.Copied, name)
+	}
+
+	return result, nil
+}
+
+// selected reports whether name passes the include and exclude patterns in opts.
+// Patterns are validated by Copy before use.
+func selected(name string, opts CopyOptions) bool {
+	for _, pattern := range opts.Exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+
+	if len(opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range opts.Include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// checkpoint records the secrets a copy has completed in an append-only file, one path
+// per line, so an interrupted copy can be restarted without redoing finished work.
+//
+// Each path is appended with a single write, so a crash can at worst leave a partial
+// final line; such a line is ignored on load. A nil *checkpoint is valid and records
+// nothing.
+type checkpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]struct{}
+}
+
+// openCheckpoint loads the completed paths from path and opens it for appending. It
+// returns a nil checkpoint when path is empty.
+func openCheckpoint(path string) (*checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	lines := strings.Split(string(raw), "\n")
+	// the last element is either empty or an incomplete line from an interrupted write
+	lines = lines[:len(lines)-1]
+
+	done := make(map[string]struct{}, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			done[line] = struct{}{}
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	// terminate a partial final line so the next record starts cleanly
+	if len(raw) > 0 && raw[len(raw)-1] != '\n' {
+		if _, err := file.WriteString("\n"); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return &checkpoint{file: file, done: done}, nil
+}
+
+// Done reports whether secretPath was recorded as completed by a previous run.
+func (c *checkpoint) Done(secretPath string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.done[secretPath]
+	return ok
+}
+
+// Record appends secretPath to the checkpoint file.
+func (c *checkpoint) Record(secretPath string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.WriteString(secretPath + "\n"); err != nil {
+		return err
+	}
+	c.done[secretPath] = struct{}{}
+	return nil
+}
+
+// Close closes the checkpoint file.
+func (c *checkpoint) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.file.Close()
+}
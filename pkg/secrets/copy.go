@@ -0,0 +1,641 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// CopyOptions controls what CopySecrets copies and how.
+type CopyOptions struct {
+	// SourceMount and TargetMount are the KV mounts to copy from and to.
+	SourceMount string
+	TargetMount string
+	// TargetPrefix is prepended to every secret path written to the target mount.
+	TargetPrefix string
+	// Prefix limits traversal to secrets under this path within the source mount.
+	Prefix string
+	// Path copies only this secret within the source mount, or this subtree if it ends
+	// in "/". It replaces Prefix when set.
+	Path string
+	// SourcePath limits traversal to secrets under this path within the source mount,
+	// like Prefix, but copies them relative to it: SourcePath/app/db is written to app/db
+	// (under TargetPrefix, if set). It replaces Prefix and Path when set.
+	SourcePath string
+	// Paths copies exactly these secrets within the source mount, without traversing it.
+	// It replaces Prefix, Path and SourcePath when set. Paths missing on the source are logged and
+	// counted as failed.
+	Paths []string
+	// PathMap rewrites the target path of individual secrets: each key is a full source
+	// path, including the source mount, and its value the path within the target mount
+	// to copy that secret to, used as is without TargetPrefix. Secrets not in the map get
+	// the default target path. MapOnly copies exactly the map's secrets on the source
+	// mount instead, without traversing it, like Paths; it replaces Prefix, Path,
+	// SourcePath and Paths, and entries missing on the source are counted as failed.
+	PathMap map[string]string
+	MapOnly bool
+	// ResumeFrom, when set, leaves out every secret whose path within the source mount
+	// sorts lexically before it, so an interrupted copy can be restarted by hand at the
+	// last secret it reached. Secrets are copied in sorted path order when it is set.
+	ResumeFrom string
+	// MaxDepth limits how many path levels traversal descends; 0 is unlimited.
+	MaxDepth int
+	// MaxSecrets makes planning fail with ErrSecretLimit, before anything is written,
+	// when more than this many secrets are selected on the source mount; 0 is unlimited.
+	MaxSecrets int
+	// Since skips KV v2 secrets not updated at or after this time when non-zero.
+	Since time.Time
+	// AllVersions replays every KV v2 version, preserving deleted and destroyed state.
+	AllVersions bool
+	// SourceVersion, when set, copies this version of each KV v2 secret instead of the
+	// latest, e.g. to reproduce a known-good snapshot. Secrets without a readable version
+	// of that number are skipped with a warning. It requires a KV v2 source and replaces
+	// AllVersions.
+	SourceVersion int
+	// Strict aborts when the target token lacks write capability, instead of warning,
+	// and fails secrets that exceed MaxSecretSize instead of attempting them.
+	Strict bool
+	// MaxSecretSize flags secrets whose data serializes to more bytes than this, checked
+	// after each is read and before it is written; 0 disables the check. See
+	// DefaultMaxSecretSize.
+	MaxSecretSize int
+	// Preflight checks health, token TTL and capabilities on both instances first, while
+	// planning and before the source is listed.
+	Preflight bool
+	// RateLimit is the maximum number of Vault requests per second; 0 is unlimited.
+	RateLimit float64
+	// CheckpointFile records completed secrets so a restarted copy can skip them.
+	CheckpointFile string
+	// KVVersion forces "1" or "2" behavior instead of detecting the source mount's version.
+	// Unless TargetKVVersion is set, it applies to the target mount too.
+	KVVersion string
+	// TargetKVVersion forces how secrets are written to the target mount instead of
+	// detecting its version. Secrets are translated when it differs from the source.
+	TargetKVVersion string
+	// Overwrite replaces secrets that already exist on the target. Without it they are
+	// skipped.
+	Overwrite bool
+	// WithMetadataConfig copies each KV v2 secret's max_versions, cas_required and
+	// delete_version_after settings to the target.
+	WithMetadataConfig bool
+	// Merge writes the union of the source secret's keys and those of an existing target
+	// secret instead of replacing it. Source values win on conflicting keys unless
+	// MergePreferTarget is set. Merge implies Overwrite for secrets that exist.
+	Merge             bool
+	MergePreferTarget bool
+	// Manifest, when set, receives a checksum of each copied secret as read from the
+	// source and read back from the target.
+	Manifest *Manifest
+	// Duplicates, when set, receives the data of each secret read from the source, to
+	// report secrets holding identical data. Secrets copied with AllVersions, and those
+	// skipped before being read, are not recorded.
+	Duplicates *DuplicateIndex
+	// Threads is the number of secrets copied concurrently; 0 or 1 copies one at a time.
+	// With several threads each log line carries a "worker" attr, and each secret's
+	// lines are logged together once it is done.
+	Threads int
+	// Sort logs a final per-secret result line in path order once all secrets are done,
+	// so runs can be compared even when Threads makes progress logging interleave.
+	Sort bool
+	// IncludeKeys, when set, copies only these top-level fields of each secret.
+	// ExcludeKeys drops these fields, and wins over IncludeKeys.
+	IncludeKeys []string
+	ExcludeKeys []string
+	// WriteOptions is sent as the options object of every KV v2 write to the target,
+	// e.g. {"cas": 0}. It is ignored when the target is KV v1, and for the history
+	// replayed by AllVersions.
+	WriteOptions map[string]interface{}
+	// Dereference replaces references to other secrets' fields (see RefPrefix) with the
+	// values they point to, read from the source, before writing.
+	Dereference bool
+	// CreateTargetMount enables the target mount, as a KV engine of TargetKVVersion or
+	// else the source's version, if it doesn't exist yet. An existing mount is left as is.
+	CreateTargetMount bool
+	// TimeoutPerSecret bounds each Vault request made to copy a secret, including
+	// metadata, version, manifest and dereference requests but not an unwrap, counted
+	// from when the rate limiter lets it through; a request that exceeds it is retried
+	// up to three times. 0 leaves requests bounded only by ctx.
+	TimeoutPerSecret time.Duration
+	// WrapTTL, when set, response-wraps each source read with this TTL and unwraps it
+	// only once nothing but in-memory processing, Dereference reads and the write are
+	// left, after the target was read for Merge. The read response itself carries only
+	// a single-use token and the audit log records the read as wrapped. A token that
+	// was already unwrapped, e.g. by someone who intercepted it, fails the secret. The
+	// history replayed by AllVersions and reads made by Dereference are not wrapped.
+	WrapTTL time.Duration
+}
+
+// CopyResult reports what a copy did with each secret it planned, by source path
+// (including the source mount), in plan order.
+type CopyResult struct {
+	Copied  []string // secrets written to the target
+	Skipped []string // secrets deliberately left untouched
+	Failed  []string // secrets that could not be copied
+	// Errors holds why each secret in Failed could not be copied.
+	Errors map[string]error
+	// NotStarted lists the secrets left untouched because ctx was cancelled, e.g. by
+	// Ctrl-C, before they were reached. Secrets already in flight are finished first.
+	NotStarted []string
+}
+
+// CopySecrets copies the secrets selected by opts from the source mount on sourceClient
+// to the target mount on targetClient, preserving their paths relative to the mount.
+// Secrets are read in the source mount's KV format and written in the target's, so a KV
+// v1 mount can be copied to a KV v2 mount and vice versa.
+//
+// Values are copied with their JSON types intact: numbers stay numbers of the same
+// precision and booleans stay booleans. Secrets that already exist on the target are
+// skipped unless opts.Overwrite or opts.Merge is set.
+// With opts.Threads above 1 secrets are copied concurrently, sharing one rate limiter.
+// Secrets that fail to copy are logged and reported in the result's Failed list, with
+// the reason in Errors; the returned error is reserved for problems that stop the whole
+// run, such as either Vault being sealed (ErrVaultSealed). Cancelling ctx stops the run
+// once the secrets in flight are done; the partial result is returned along with an
+// error wrapping ctx.Err().
+func CopySecrets(ctx context.Context, sourceClient, targetClient *vault.Client, opts CopyOptions) (*CopyResult, error) {
+	plan, err := PlanCopy(ctx, sourceClient, targetClient, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ExecuteCopyPlan(ctx, sourceClient, targetClient, plan, opts)
+}
+
+// ExecuteCopyPlan copies the secrets listed in plan, as built by PlanCopy with the same
+// opts. It enables the target mount first if the plan calls for it, and checks the target
+// token's write capabilities before writing anything; the preflight opts asks for has
+// already run in PlanCopy. Like CopySecrets, it returns an error only for problems that
+// stop the whole run.
+func ExecuteCopyPlan(ctx context.Context, sourceClient, targetClient *vault.Client, plan *CopyPlan, opts CopyOptions) (*CopyResult, error) {
+	sourceMount := plan.SourceMount
+	targetMount := plan.TargetMount
+	kvVersion := plan.SourceVersion
+	targetVersion := plan.TargetVersion
+	targetPrefix := strings.Trim(opts.TargetPrefix, "/")
+	limiter := newRateLimiter(opts.RateLimit)
+
+	if plan.CreateTargetMount {
+		// an existing KV mount of the other version is used as is, translating secrets
+		created, err := EnableKVMount(ctx, targetClient, targetMount, targetVersion)
+		if err != nil && !errors.Is(err, ErrMountConflict) {
+			return nil, fmt.Errorf("failed to create target mount: %w", err)
+		}
+		if created {
+			slog.Info("created target mount", "mount", targetMount, "version", targetVersion)
+		}
+	}
+
+	writePaths := []string{kvCapabilityPath(targetMount, targetVersion, "write", targetPrefix)}
+	if err := checkWriteCapabilities(ctx, targetClient, writePaths, opts.Strict); err != nil {
+		return nil, fmt.Errorf("target capability check failed: %w", err)
+	}
+
+	since := opts.Since
+	if !since.IsZero() && kvVersion != "2" {
+		slog.Warn("Since requires KV v2 metadata, ignoring the filter", "version", kvVersion)
+		since = time.Time{}
+	}
+
+	if len(opts.WriteOptions) > 0 && targetVersion != "2" {
+		slog.Warn("WriteOptions require a KV v2 target, ignoring them", "target_version", targetVersion)
+	}
+
+	var refMounts map[string]MountInfo
+	if opts.Dereference {
+		var err error
+		refMounts, err = GetSecretEngines(ctx, sourceClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list source mounts for dereferencing: %w", err)
+		}
+		if opts.KVVersion != "" {
+			for mountPath, mountInfo := range refMounts {
+				mountInfo.Version = opts.KVVersion
+				refMounts[mountPath] = mountInfo
+			}
+		}
+	}
+
+	cp, err := openCheckpoint(opts.CheckpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+	defer cp.Close()
+
+	job := &copyJob{
+		sourceClient:       sourceClient,
+		targetClient:       targetClient,
+		limiter:            limiter,
+		sourceMount:        sourceMount,
+		targetMount:        targetMount,
+		kvVersion:          kvVersion,
+		targetVersion:      targetVersion,
+		since:              since,
+		allVersions:        plan.AllVersions,
+		overwrite:          opts.Overwrite,
+		checkpoint:         cp,
+		withMetadataConfig: plan.WithMetadataConfig,
+		manifest:           opts.Manifest,
+		duplicates:         opts.Duplicates,
+		merge:              opts.Merge,
+		mergePreferTarget:  opts.MergePreferTarget,
+		timeoutPerSecret:   opts.TimeoutPerSecret,
+		checkSource:        len(opts.Paths) > 0 || opts.MapOnly,
+		keys:               newKeyFilter(opts.IncludeKeys, opts.ExcludeKeys),
+		refMounts:          refMounts,
+		writeOptions:       opts.WriteOptions,
+		maxSecretSize:      opts.MaxSecretSize,
+		strict:             opts.Strict,
+		wrapTTL:            opts.WrapTTL,
+		sourceVersion:      plan.PinnedVersion,
+	}
+
+	// secrets already being copied when ctx is cancelled are finished rather than
+	// abandoned halfway, so only dispatching new ones stops on an interrupt
+	workCtx := context.WithoutCancel(ctx)
+
+	// each worker writes only its own secrets' entries, so statuses needs no lock
+	items := plan.Items
+	statuses := make([]copyStatus, len(items))
+	for i := range statuses {
+		statuses[i] = statusNotStarted
+	}
+	errs := make([]error, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	threads := max(opts.Threads, 1)
+	for worker := range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workerCtx := workCtx
+			if threads > 1 {
+				workerCtx = logging.WithAttrs(workCtx, slog.Int("worker", worker))
+			}
+			for i := range indexes {
+				// with several workers, each secret's lines are written together once
+				// it is done, so they aren't interleaved with other secrets'
+				secretCtx, flush := workerCtx, func() {}
+				if threads > 1 {
+					secretCtx, flush = logging.Buffered(workerCtx)
+				}
+				statuses[i], errs[i] = job.copySecret(secretCtx, items[i])
+				if errs[i] != nil {
+					slog.ErrorContext(secretCtx, "failed to copy secret", "path", items[i].SourcePath, "error", errs[i])
+				}
+				flush()
+			}
+		}()
+	}
+dispatch:
+	for i := range items {
+		// select picks at random when both cases are ready, so check for an interrupt
+		// first rather than let it dispatch a few more secrets
+		if ctx.Err() != nil {
+			slog.Warn("copy interrupted, finishing secrets in flight", "not_started", len(items)-i)
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			slog.Warn("copy interrupted, finishing secrets in flight", "not_started", len(items)-i)
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if opts.Sort {
+		order := make([]int, len(items))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool { return items[order[a]].SourcePath < items[order[b]].SourcePath })
+		for _, i := range order {
+			slog.Info("copy result", "path", items[i].SourcePath, "status", statuses[i])
+		}
+	}
+
+	result := &CopyResult{Errors: map[string]error{}}
+	for i, item := range items {
+		switch statuses[i] {
+		case statusCopied:
+			result.Copied = append(result.Copied, item.SourcePath)
+		case statusSkipped:
+			result.Skipped = append(result.Skipped, item.SourcePath)
+		case statusNotStarted:
+			result.NotStarted = append(result.NotStarted, item.SourcePath)
+		default:
+			result.Failed = append(result.Failed, item.SourcePath)
+			result.Errors[item.SourcePath] = errs[i]
+		}
+	}
+	// an interrupt after the last secret was dispatched still fails the run, so the
+	// exit status matches the interrupted summary
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("copy interrupted: %w", ctx.Err())
+	}
+	return result, nil
+}
+
+// copyStatus is the outcome of copying a single secret.
+type copyStatus string
+
+const (
+	statusCopied  copyStatus = "copied"
+	statusSkipped copyStatus = "skipped"
+	statusFailed  copyStatus = "failed"
+	// statusNotStarted marks secrets never dispatched because the run was interrupted.
+	statusNotStarted copyStatus = "not started"
+)
+
+// copyJob holds the settings shared by every secret in one CopySecrets run. Its
+// copySecret method is safe to call from several goroutines.
+type copyJob struct {
+	sourceClient       *vault.Client
+	targetClient       *vault.Client
+	limiter            *rate.Limiter
+	sourceMount        string
+	targetMount        string
+	kvVersion          string
+	targetVersion      string
+	since              time.Time
+	allVersions        bool
+	overwrite          bool
+	checkpoint         *checkpoint
+	withMetadataConfig bool
+	manifest           *Manifest
+	duplicates         *DuplicateIndex
+	merge              bool
+	mergePreferTarget  bool
+	timeoutPerSecret   time.Duration
+	// checkSource confirms each secret exists on the source before copying it, for
+	// paths that were listed rather than discovered by traversal
+	checkSource bool
+	keys        keyFilter
+	// refMounts routes references when dereferencing; nil leaves references as they are
+	refMounts     map[string]MountInfo
+	writeOptions  map[string]interface{}
+	maxSecretSize int
+	strict        bool
+	wrapTTL       time.Duration
+	// sourceVersion pins the KV v2 version read from the source; 0 reads the latest
+	sourceVersion int
+}
+
+// copySecret copies the secret planned by item and reports whether it was copied,
+// skipped or failed, with the reason for a failure. The secret is read in the source
+// mount's KV format and written in the target's, so differing versions are translated.
+func (j *copyJob) copySecret(ctx context.Context, item CopyPlanItem) (copyStatus, error) {
+	fullPath, targetPath := item.SourcePath, item.TargetPath
+	// bounds every Vault request made for this secret, including those of the helpers
+	// below, each from when the rate limiter lets it through
+	ctx = vaultclient.WithRequestTimeout(ctx, j.timeoutPerSecret)
+	if j.checkpoint.Done(fullPath) {
+		slog.InfoContext(ctx, "skipping secret already copied per checkpoint", "path", fullPath)
+		return statusSkipped, nil
+	}
+
+	relativePath := RelativeSecretPath(j.sourceMount, fullPath)
+	sourceInfo := MountInfo{MountPath: j.sourceMount, Version: j.kvVersion}
+	targetInfo := MountInfo{MountPath: j.targetMount, Version: j.targetVersion}
+
+	if j.checkSource {
+		exists, err := secretExists(ctx, j.sourceClient, j.limiter, sourceInfo, relativePath)
+		if err != nil {
+			return statusFailed, fmt.Errorf("failed to check for secret on source mount: %w", err)
+		}
+		if !exists {
+			return statusFailed, errors.New("listed secret does not exist on source")
+		}
+	}
+
+	if !j.overwrite && !j.merge {
+		exists, err := secretExists(ctx, j.targetClient, j.limiter, targetInfo, targetPath)
+		if err != nil {
+			return statusFailed, fmt.Errorf("failed to check for existing secret %q on target mount: %w", targetPath, err)
+		}
+		if exists {
+			slog.InfoContext(ctx, "secret already exists on target and Overwrite is not set, skipping", "path", targetPath)
+			return statusSkipped, nil
+		}
+	}
+
+	if !j.since.IsZero() {
+		var metadata *vault.Response[schema.KvV2ReadMetadataResponse]
+		err := vaultclient.WithRetry(ctx, j.sourceClient, j.limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+			metadata, err = j.sourceClient.Secrets.KvV2ReadMetadata(ctx, relativePath, vault.WithMountPath(j.sourceMount), opt)
+			return err
+		})
+		if err != nil {
+			return statusFailed, fmt.Errorf("failed to read KV v2 metadata: %w", err)
+		}
+		if metadata.Data.UpdatedTime.Before(j.since) {
+			slog.InfoContext(ctx, "skipping KV v2 secret not updated since cutoff", "path", relativePath, "updated_time", metadata.Data.UpdatedTime)
+			return statusSkipped, nil
+		}
+	}
+
+	if j.allVersions {
+		if err := copyAllVersions(ctx, j.sourceClient, j.targetClient, j.limiter, j.sourceMount, j.targetMount, relativePath, targetPath, j.keys); err != nil {
+			return statusFailed, fmt.Errorf("failed to copy KV v2 secret versions: %w", err)
+		}
+		if err := j.copyMetadataConfig(ctx, relativePath, targetPath); err != nil {
+			return statusFailed, err
+		}
+		slog.InfoContext(ctx, "copied all KV v2 secret versions", "path", targetPath)
+		j.addToManifest(ctx, fullPath, targetPath, nil)
+		j.record(ctx, fullPath)
+		return statusCopied, nil
+	}
+
+	data, token, err := j.readSource(ctx, sourceInfo, relativePath)
+	if j.sourceVersion > 0 && vault.IsErrorStatus(err, http.StatusNotFound) {
+		slog.WarnContext(ctx, "secret has no readable version of that number, skipping", "path", fullPath, "version", j.sourceVersion)
+		return statusSkipped, nil
+	}
+	if err != nil {
+		return statusFailed, err
+	}
+
+	// with a wrapped read, the target is read for the merge while the secret is still
+	// wrapped, so that only in-memory processing and any dereference reads separate the
+	// unwrap from the write
+	var existing map[string]interface{}
+	if j.merge {
+		existing, err = ReadSecret(ctx, j.targetClient, j.limiter, targetInfo, targetPath)
+		if err != nil && !vault.IsErrorStatus(err, http.StatusNotFound) {
+			return statusFailed, fmt.Errorf("failed to read existing target secret %q to merge into: %w", targetPath, err)
+		}
+	}
+	if token != "" {
+		// UnwrapSecret bypasses WithRetry, so TimeoutPerSecret doesn't bound it: abandoning
+		// the unwrap midway could spend the single-use token without delivering the secret
+		if data, err = UnwrapSecret(ctx, j.sourceClient, j.limiter, sourceInfo, token); err != nil {
+			return statusFailed, fmt.Errorf("failed to unwrap secret read from source: %w", err)
+		}
+	}
+
+	if data == nil {
+		slog.WarnContext(ctx, "no data found at secret", "path", fullPath)
+	}
+	if j.refMounts != nil {
+		if data, err = dereference(ctx, j.sourceClient, j.limiter, j.refMounts, fullPath, data); err != nil {
+			return statusFailed, fmt.Errorf("failed to dereference secret: %w", err)
+		}
+	}
+	data = j.keys.apply(data)
+	if j.duplicates != nil {
+		if err := j.duplicates.Add(fullPath, data); err != nil {
+			slog.ErrorContext(ctx, "failed to checksum secret for duplicate detection", "path", fullPath, "error", err)
+		}
+	}
+
+	// the manifest checksums what the source holds, not what the merge made of it
+	sourceData := data
+	if j.merge {
+		data = mergeSecretData(existing, data, j.mergePreferTarget)
+	}
+
+	if err := checkSecretSize(ctx, fullPath, data, j.maxSecretSize, j.strict); err != nil {
+		return statusFailed, err
+	}
+
+	if _, err := WriteSecretWithOptions(ctx, j.targetClient, j.limiter, targetInfo, targetPath, data, j.writeOptions); err != nil {
+		return statusFailed, fmt.Errorf("failed to write secret %q to KV v%s target: %w", targetPath, j.targetVersion, err)
+	}
+	if err := j.copyMetadataConfig(ctx, relativePath, targetPath); err != nil {
+		return statusFailed, err
+	}
+
+	slog.InfoContext(ctx, "copied secret", "path", targetPath, "source_version", j.kvVersion, "target_version", j.targetVersion)
+	j.addToManifest(ctx, fullPath, targetPath, sourceData)
+	j.record(ctx, fullPath)
+	return statusCopied, nil
+}
+
+// readSource reads the secret at relativePath from the source mount, or the job's pinned
+// version of it. When the job has a wrap TTL the read is response-wrapped and only the
+// wrapping token is returned, for the caller to unwrap just before writing; otherwise
+// the token is empty.
+func (j *copyJob) readSource(ctx context.Context, sourceInfo MountInfo, relativePath string) (map[string]interface{}, string, error) {
+	if j.wrapTTL <= 0 {
+		var data map[string]interface{}
+		var err error
+		if j.sourceVersion > 0 {
+			data, err = ReadSecretVersion(ctx, j.sourceClient, j.limiter, j.sourceMount, relativePath, j.sourceVersion)
+		} else {
+			data, err = ReadSecret(ctx, j.sourceClient, j.limiter, sourceInfo, relativePath)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read secret from KV v%s source: %w", j.kvVersion, err)
+		}
+		return data, "", nil
+	}
+
+	var token string
+	var err error
+	if j.sourceVersion > 0 {
+		token, err = readSecretWrapped(ctx, j.sourceClient, j.limiter, sourceInfo, relativePath, j.wrapTTL, versionParameter(j.sourceVersion))
+	} else {
+		token, err = ReadSecretWrapped(ctx, j.sourceClient, j.limiter, sourceInfo, relativePath, j.wrapTTL)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read wrapped secret from KV v%s source: %w", j.kvVersion, err)
+	}
+	return nil, token, nil
+}
+
+// addToManifest checksums the copied secret on both sides and adds it to the job's
+// manifest, if any. sourceData is the data read from the source, before any merge with
+// the target, or nil to read the latest version from the source, filtered like the copy.
+// With a merge the target also keeps its own fields, so a mismatch is expected and not
+// warned about. Failures are logged and leave the secret out of the manifest.
+func (j *copyJob) addToManifest(ctx context.Context, fullPath, targetPath string, sourceData map[string]interface{}) {
+	if j.manifest == nil {
+		return
+	}
+
+	relativePath := RelativeSecretPath(j.sourceMount, fullPath)
+	if sourceData == nil {
+		data, err := ReadSecret(ctx, j.sourceClient, j.limiter, MountInfo{MountPath: j.sourceMount, Version: j.kvVersion}, relativePath)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to read source secret for manifest", "path", fullPath, "error", err)
+			return
+		}
+		sourceData = j.keys.apply(data)
+	}
+	targetData, err := ReadSecret(ctx, j.targetClient, j.limiter, MountInfo{MountPath: j.targetMount, Version: j.targetVersion}, targetPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to read back target secret for manifest", "path", targetPath, "error", err)
+		return
+	}
+
+	sourceSum, err := DataChecksum(sourceData)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to checksum source secret", "path", fullPath, "error", err)
+		return
+	}
+	targetSum, err := DataChecksum(targetData)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to checksum target secret", "path", targetPath, "error", err)
+		return
+	}
+	if sourceSum != targetSum && !j.merge {
+		slog.WarnContext(ctx, "target secret differs from source after copy", "path", targetPath)
+	}
+
+	j.manifest.add(ManifestEntry{
+		SourcePath:   fullPath,
+		TargetPath:   path.Join(j.targetMount, targetPath),
+		SourceSHA256: sourceSum,
+		TargetSHA256: targetSum,
+		Match:        sourceSum == targetSum,
+	})
+}
+
+// record marks fullPath as done in the checkpoint, logging any failure.
+func (j *copyJob) record(ctx context.Context, fullPath string) {
+	if err := j.checkpoint.Record(fullPath); err != nil {
+		slog.ErrorContext(ctx, "failed to update checkpoint file", "path", fullPath, "error", err)
+	}
+}
+
+// copyMetadataConfig copies the secret's metadata settings when the job asks for it.
+func (j *copyJob) copyMetadataConfig(ctx context.Context, relativePath, targetPath string) error {
+	if !j.withMetadataConfig {
+		return nil
+	}
+	if err := copyMetadataConfig(ctx, j.sourceClient, j.targetClient, j.limiter, j.sourceMount, j.targetMount, relativePath, targetPath); err != nil {
+		return fmt.Errorf("failed to copy KV v2 metadata config: %w", err)
+	}
+	return nil
+}
+
+// mergeSecretData returns the union of the keys in existing and source. Conflicting keys
+// take the source value unless preferTarget is set. Only top-level keys are merged;
+// nested values are replaced as a whole.
+func mergeSecretData(existing, source map[string]interface{}, preferTarget bool) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(source))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range source {
+		if _, ok := merged[key]; ok && preferTarget {
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
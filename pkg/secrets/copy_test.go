@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExecuteCopyPlanResult(t *testing.T) {
+	_, source := newFakeVault(t, map[string]string{"secret": "2"}, map[string]map[string]interface{}{
+		"secret/app/api":      {"key": "api"},
+		"secret/app/db":       {"password": "db"},
+		"secret/app/broken":   {"password": "broken"},
+		"secret/app/existing": {"password": "new"},
+	})
+	target, targetClient := newFakeVault(t, map[string]string{"secret": "2"}, map[string]map[string]interface{}{
+		"secret/app/existing": {"password": "old"},
+	})
+	target.failWrites["secret/app/broken"] = true
+
+	opts := CopyOptions{SourceMount: "secret", TargetMount: "secret", Threads: 2}
+	plan, err := PlanCopy(context.Background(), source, targetClient, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ExecuteCopyPlan(context.Background(), source, targetClient, plan, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"secret/app/api", "secret/app/db"}; !reflect.DeepEqual(result.Copied, want) {
+		t.Errorf("Copied = %v, want %v", result.Copied, want)
+	}
+	if want := []string{"secret/app/existing"}; !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, want)
+	}
+	if want := []string{"secret/app/broken"}; !reflect.DeepEqual(result.Failed, want) {
+		t.Errorf("Failed = %v, want %v", result.Failed, want)
+	}
+	if len(result.Errors) != 1 || result.Errors["secret/app/broken"] == nil {
+		t.Errorf("Errors = %v, want the reason secret/app/broken failed only", result.Errors)
+	}
+	if len(result.NotStarted) != 0 {
+		t.Errorf("NotStarted = %v, want none", result.NotStarted)
+	}
+
+	if got := target.secret("secret/app/db"); !reflect.DeepEqual(got, map[string]interface{}{"password": "db"}) {
+		t.Errorf("target secret/app/db = %v", got)
+	}
+	if got := target.secret("secret/app/existing"); !reflect.DeepEqual(got, map[string]interface{}{"password": "old"}) {
+		t.Errorf("existing target secret overwritten: %v", got)
+	}
+}
+
+func TestExecuteCopyPlanResultInterrupted(t *testing.T) {
+	_, source := newFakeVault(t, map[string]string{"secret": "2"}, map[string]map[string]interface{}{
+		"secret/a": {"k": "a"},
+		"secret/b": {"k": "b"},
+	})
+	target, targetClient := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+	opts := CopyOptions{SourceMount: "secret", TargetMount: "secret"}
+	plan, err := PlanCopy(context.Background(), source, targetClient, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := ExecuteCopyPlan(ctx, source, targetClient, plan, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if want := []string{"secret/a", "secret/b"}; !reflect.DeepEqual(result.NotStarted, want) {
+		t.Errorf("NotStarted = %v, want %v", result.NotStarted, want)
+	}
+	if len(result.Copied)+len(result.Skipped)+len(result.Failed) != 0 {
+		t.Errorf("secrets handled after the interrupt: %+v", result)
+	}
+	if got := target.secret("secret/a"); got != nil {
+		t.Errorf("secret/a written after the interrupt: %v", got)
+	}
+}
@@ -0,0 +1,368 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"golang.org/x/time/rate"
+)
+
+// CreateOptions controls how CreateSecrets writes secrets.
+type CreateOptions struct {
+	// Strict aborts before writing anything when the token lacks write capability, or
+	// when a secret exceeds MaxSecretSize, instead of only warning.
+	Strict bool
+	// MaxSecretSize flags secrets whose data serializes to more bytes than this before
+	// anything is written; 0 disables the check. See DefaultMaxSecretSize.
+	MaxSecretSize int
+	// RateLimit is the maximum number of Vault requests per second; 0 is unlimited.
+	RateLimit float64
+	// KVVersion forces "1" or "2" behavior for every mount instead of using the
+	// detected version. Mounts are then not looked up either: each path's first segment
+	// is taken as its mount (see AssumeMount), so sys/mounts is never read.
+	KVVersion string
+	// Overwrite replaces secrets that already exist. Without it they are skipped.
+	Overwrite bool
+	// OnlyChanged reads each existing secret and writes only when its data differs from
+	// the input, so re-applying the same input creates no new KV v2 versions. It implies
+	// Overwrite for secrets that changed.
+	OnlyChanged bool
+	// WriteOptions is sent as the options object of every KV v2 write, e.g. {"cas": 0}
+	// to write only secrets that don't exist yet. It is ignored on KV v1 mounts.
+	WriteOptions map[string]interface{}
+}
+
+// CreateResult reports what CreateSecrets did with each secret path it was given.
+type CreateResult struct {
+	Written []string // secrets written to Vault
+	Skipped []string // secrets deliberately left untouched
+	Failed  []string // secrets that could not be written
+	// NotStarted lists the secrets left untouched because ctx was cancelled, e.g. by
+	// Ctrl-C, before they were reached.
+	NotStarted []string
+}
+
+// CreateSecrets writes each entry of secrets, keyed by full secret path including the
+// mount, to Vault.
+//
+// The function supports both KV v1 and KV v2 secret engines, automatically determining
+// the correct mount and version for each secret path based on the enabled secret engines
+// in Vault. KV v2 secrets are versioned automatically; KV v1 secrets are overwritten
+// directly.
+//
+// Secrets that already exist are reported in the result's Skipped list and left
+// untouched unless opts.Overwrite is set, or opts.OnlyChanged is set and their data
+// differs. Empty secret paths and field names, and paths naming a mount rather than a
+// secret under it, are rejected before anything is written. Secrets that cannot be
+// written, for example because no mount matches their path, are logged and reported in
+// the result's Failed list; the returned error is reserved for problems that stop the
+// whole run. Cancelling ctx stops the run after the secret being written; the partial
+// result is returned along with an error wrapping ctx.Err().
+//
+// A secret may carry a MetaKey ("_meta") field with KV v2 metadata (custom_metadata,
+// max_versions, cas_required, delete_version_after). It is not stored as data but applied
+// through the metadata endpoint after the data is written, and ignored with a warning on
+// KV v1 mounts.
+func CreateSecrets(ctx context.Context, client *vault.Client, secrets map[string]map[string]interface{}, opts CreateOptions) (*CreateResult, error) {
+	secrets, metas, err := splitSecretsMeta(secrets)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSecrets(secrets); err != nil {
+		return nil, err
+	}
+
+	findMount := func(secretPath string) (MountInfo, string, error) {
+		return AssumeMount(secretPath, opts.KVVersion)
+	}
+	if opts.KVVersion == "" {
+		mountsMap, err := GetSecretEngines(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list KV secret engines: %w", err)
+		}
+		findMount = func(secretPath string) (MountInfo, string, error) {
+			return FindMountForSecret(secretPath, mountsMap)
+		}
+	}
+
+	secretPaths := make([]string, 0, len(secrets))
+	for secretPath := range secrets {
+		secretPaths = append(secretPaths, secretPath)
+	}
+	sort.Strings(secretPaths)
+
+	var writePaths []string
+	usedMounts := make(map[string]MountInfo)
+	for _, secretPath := range secretPaths {
+		if mountInfo, relativePath, err := findMount(secretPath); err == nil {
+			if relativePath == "" {
+				return nil, fmt.Errorf("invalid secret path %q: it names the mount itself, not a secret under it", secretPath)
+			}
+			writePaths = append(writePaths, strings.TrimSuffix(kvCapabilityPath(mountInfo.MountPath, mountInfo.Version, "write", relativePath), "/"))
+			usedMounts[mountInfo.MountPath] = mountInfo
+		}
+	}
+	for _, mountInfo := range usedMounts {
+		if err := waitForKVUpgrade(ctx, client, mountInfo); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkWriteCapabilities(ctx, client, writePaths, opts.Strict); err != nil {
+		return nil, fmt.Errorf("capability check failed: %w", err)
+	}
+	if err := checkSecretSizes(ctx, secretPaths, secrets, opts.MaxSecretSize, opts.Strict); err != nil {
+		return nil, fmt.Errorf("size check failed: %w", err)
+	}
+	for _, secretPath := range secretPaths {
+		warnLossyNumbers(ctx, secretPath, secrets[secretPath])
+	}
+
+	limiter := newRateLimiter(opts.RateLimit)
+	result := &CreateResult{}
+
+	// the secret being written when ctx is cancelled is finished rather than abandoned
+	// halfway, so only moving on to the next one stops on an interrupt
+	interrupt := ctx
+	ctx = context.WithoutCancel(ctx)
+
+	for i, secretPath := range secretPaths {
+		if interrupt.Err() != nil {
+			slog.Warn("create interrupted", "not_started", len(secretPaths)-i)
+			result.NotStarted = secretPaths[i:]
+			return result, fmt.Errorf("create interrupted: %w", interrupt.Err())
+		}
+
+		mountInfo, relativePath, err := findMount(secretPath)
+		if err != nil {
+			slog.Error("mount not found for secret", "path", secretPath, "error", err)
+			result.Failed = append(result.Failed, secretPath)
+			continue
+		}
+
+		if opts.OnlyChanged {
+			changed, err := secretChanged(ctx, client, limiter, mountInfo, relativePath, secrets[secretPath])
+			if err != nil {
+				slog.Error("failed to compare with existing secret", "path", secretPath, "error", err)
+				result.Failed = append(result.Failed, secretPath)
+				continue
+			}
+			if !changed {
+				// metadata writes create no new version, so settings changed on their
+				// own still apply
+				applyMeta(ctx, client, limiter, mountInfo, relativePath, secretPath, metas[secretPath])
+				slog.Info("secret unchanged, skipping", "path", secretPath)
+				result.Skipped = append(result.Skipped, secretPath)
+				continue
+			}
+		} else if !opts.Overwrite {
+			exists, err := secretExists(ctx, client, limiter, mountInfo, relativePath)
+			if err != nil {
+				slog.Error("failed to check for existing secret", "path", secretPath, "error", err)
+				result.Failed = append(result.Failed, secretPath)
+				continue
+			}
+			if exists {
+				slog.Info("secret already exists and Overwrite is not set, skipping", "path", secretPath)
+				result.Skipped = append(result.Skipped, secretPath)
+				continue
+			}
+		}
+
+		version, err := WriteSecretWithOptions(ctx, client, limiter, mountInfo, relativePath, secrets[secretPath], opts.WriteOptions)
+		if err != nil {
+			slog.Error("failed to write secret", "path", secretPath, "kv_version", mountInfo.Version, "error", err)
+			result.Failed = append(result.Failed, secretPath)
+			continue
+		}
+
+		if !applyMeta(ctx, client, limiter, mountInfo, relativePath, secretPath, metas[secretPath]) {
+			result.Failed = append(result.Failed, secretPath)
+			continue
+		}
+
+		if mountInfo.Version == "2" {
+			slog.Info("KV v2 secret written", "path", secretPath, "version", version)
+		} else {
+			slog.Info("KV v1 secret written", "path", secretPath)
+		}
+		result.Written = append(result.Written, secretPath)
+	}
+
+	return result, nil
+}
+
+// ValidateSecrets checks secrets as CreateSecrets would, without writing anything, and
+// returns every problem found joined into one error, or nil if the input is valid.
+//
+// Beyond the checks CreateSecrets makes before writing (empty paths and field names,
+// paths naming a mount, malformed MetaKey blocks), it reports secrets whose path matches
+// no KV mount on the server and values of types that cannot be stored as JSON.
+func ValidateSecrets(ctx context.Context, client *vault.Client, secrets map[string]map[string]interface{}) error {
+	mountsMap, err := GetSecretEngines(ctx, client)
+	if err != nil {
+		return fmt.Errorf("unable to list KV secret engines: %w", err)
+	}
+
+	var errs []error
+	stripped := make(map[string]map[string]interface{}, len(secrets))
+	for secretPath, secret := range secrets {
+		data, _, err := splitMeta(secret)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid secret %q: %w", secretPath, err))
+			data = secret
+		}
+		stripped[secretPath] = data
+	}
+	errs = append(errs, secretsErrors(stripped)...)
+
+	for secretPath, data := range stripped {
+		if emptySecretPath(secretPath) {
+			continue
+		}
+		for _, field := range unsupportedValueKeys("", data) {
+			errs = append(errs, fmt.Errorf("invalid secret %q: unsupported value type at %q", secretPath, field))
+		}
+
+		_, relativePath, err := FindMountForSecret(secretPath, mountsMap)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("invalid secret path %q: %w", secretPath, err))
+		case relativePath == "":
+			errs = append(errs, fmt.Errorf("invalid secret path %q: it names the mount itself, not a secret under it", secretPath))
+		}
+	}
+	return joinSorted(errs)
+}
+
+// splitSecretsMeta returns a copy of secrets with each secret's MetaKey block removed,
+// and the decoded blocks keyed by secret path. The input is not modified.
+func splitSecretsMeta(secrets map[string]map[string]interface{}) (map[string]map[string]interface{}, map[string]*SecretMeta, error) {
+	data := make(map[string]map[string]interface{}, len(secrets))
+	metas := make(map[string]*SecretMeta)
+	var errs []error
+	for secretPath, secret := range secrets {
+		stripped, meta, err := splitMeta(secret)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid secret %q: %w", secretPath, err))
+			continue
+		}
+		data[secretPath] = stripped
+		if meta != nil {
+			metas[secretPath] = meta
+		}
+	}
+	if err := joinSorted(errs); err != nil {
+		return nil, nil, err
+	}
+	return data, metas, nil
+}
+
+// applyMeta writes meta, if any, to the secret after its data, logging any failure, and
+// reports whether the secret can be counted as written. KV v1 has no metadata, so meta
+// is ignored there with a warning. The origin recorded by an export is only logged.
+func applyMeta(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath, secretPath string, meta *SecretMeta) bool {
+	if meta == nil {
+		return true
+	}
+	if meta.Version != 0 || meta.CreatedTime != "" || meta.UpdatedTime != "" {
+		slog.Info("secret restored from export", "path", secretPath, "original_version", meta.Version, "original_created_time", meta.CreatedTime, "original_updated_time", meta.UpdatedTime)
+	}
+	if !meta.hasSettings() {
+		return true
+	}
+	if mountInfo.Version != "2" {
+		slog.Warn("ignoring "+MetaKey+" block, metadata requires KV v2", "path", secretPath, "kv_version", mountInfo.Version)
+		return true
+	}
+	if err := writeSecretMeta(ctx, client, limiter, NormalizeMount(mountInfo.MountPath), relativePath, meta); err != nil {
+		slog.Error("failed to write KV v2 metadata", "path", secretPath, "error", err)
+		return false
+	}
+	return true
+}
+
+// secretChanged reports whether data differs from the secret stored at relativePath,
+// comparing canonical checksums so key order doesn't matter. A missing secret counts as
+// changed.
+func secretChanged(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string, data map[string]interface{}) (bool, error) {
+	existing, err := ReadSecret(ctx, client, limiter, mountInfo, relativePath)
+	if vault.IsErrorStatus(err, http.StatusNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	existingSum, err := DataChecksum(existing)
+	if err != nil {
+		return false, err
+	}
+	sum, err := DataChecksum(data)
+	if err != nil {
+		return false, err
+	}
+	return existingSum != sum, nil
+}
+
+// validateSecrets rejects empty or whitespace-only secret paths and field names, at any
+// nesting level, naming every offending entry.
+func validateSecrets(secrets map[string]map[string]interface{}) error {
+	return joinSorted(secretsErrors(secrets))
+}
+
+// secretsErrors returns one error per empty secret path or field name in secrets.
+func secretsErrors(secrets map[string]map[string]interface{}) []error {
+	var errs []error
+	for secretPath, data := range secrets {
+		if emptySecretPath(secretPath) {
+			errs = append(errs, fmt.Errorf("invalid secret path %q: path is empty", secretPath))
+			continue
+		}
+		for _, field := range emptyFieldNames("", data) {
+			errs = append(errs, fmt.Errorf("invalid secret %q: empty field name at %q", secretPath, field))
+		}
+	}
+	return errs
+}
+
+// emptySecretPath reports whether secretPath is empty once slashes and whitespace are
+// trimmed.
+func emptySecretPath(secretPath string) bool {
+	return strings.TrimSpace(strings.Trim(secretPath, "/")) == ""
+}
+
+// joinSorted joins errs into one error, or returns nil if there are none. They are
+// sorted for a stable message regardless of map order.
+func joinSorted(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// emptyFieldNames returns the dotted location of each empty or whitespace-only key in
+// data and its nested maps.
+func emptyFieldNames(prefix string, data map[string]interface{}) []string {
+	var fields []string
+	for key, value := range data {
+		location := key
+		if prefix != "" {
+			location = prefix + "." + key
+		}
+		if strings.TrimSpace(key) == "" {
+			fields = append(fields, location)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			fields = append(fields, emptyFieldNames(location, nested)...)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCreateSecretsResult(t *testing.T) {
+	secrets := map[string]map[string]interface{}{
+		"kv/new":          {"password": "v1"},
+		"nomount/app":     {"password": "lost"},
+		"secret/broken":   {"password": "broken"},
+		"secret/existing": {"password": "new"},
+		"secret/new":      {"password": "v2"},
+		"secret/same":     {"password": "same"},
+	}
+	existing := map[string]map[string]interface{}{
+		"secret/existing": {"password": "old"},
+		"secret/same":     {"password": "same"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        CreateOptions
+		wantWritten []string
+		wantSkipped []string
+	}{
+		{
+			name:        "existing secrets skipped",
+			wantWritten: []string{"kv/new", "secret/new"},
+			wantSkipped: []string{"secret/existing", "secret/same"},
+		},
+		{
+			name:        "overwrite",
+			opts:        CreateOptions{Overwrite: true},
+			wantWritten: []string{"kv/new", "secret/existing", "secret/new", "secret/same"},
+		},
+		{
+			name:        "only changed",
+			opts:        CreateOptions{OnlyChanged: true},
+			wantWritten: []string{"kv/new", "secret/existing", "secret/new"},
+			wantSkipped: []string{"secret/same"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fv, client := newFakeVault(t, map[string]string{"kv": "1", "secret": "2"}, existing)
+			fv.failWrites["secret/broken"] = true
+
+			result, err := CreateSecrets(context.Background(), client, secrets, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Written, tt.wantWritten) {
+				t.Errorf("Written = %v, want %v", result.Written, tt.wantWritten)
+			}
+			if !reflect.DeepEqual(result.Skipped, tt.wantSkipped) {
+				t.Errorf("Skipped = %v, want %v", result.Skipped, tt.wantSkipped)
+			}
+			if want := []string{"nomount/app", "secret/broken"}; !reflect.DeepEqual(result.Failed, want) {
+				t.Errorf("Failed = %v, want %v", result.Failed, want)
+			}
+			if len(result.NotStarted) != 0 {
+				t.Errorf("NotStarted = %v, want none", result.NotStarted)
+			}
+			for _, secretPath := range result.Written {
+				if got := fv.secret(secretPath); !reflect.DeepEqual(got, secrets[secretPath]) {
+					t.Errorf("%s = %v after the write, want %v", secretPath, got, secrets[secretPath])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateSecretsResultInterrupted(t *testing.T) {
+	fv, client := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+	// the interrupt arrives while the first secret is being written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fv.onWrite = func(string) { cancel() }
+	result, err := CreateSecrets(ctx, client, map[string]map[string]interface{}{
+		"secret/a": {"k": "a"},
+		"secret/b": {"k": "b"},
+	}, CreateOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if want := []string{"secret/a"}; !reflect.DeepEqual(result.Written, want) {
+		t.Errorf("Written = %v, want the secret in flight, %v", result.Written, want)
+	}
+	if want := []string{"secret/b"}; !reflect.DeepEqual(result.NotStarted, want) {
+		t.Errorf("NotStarted = %v, want %v", result.NotStarted, want)
+	}
+	if got := fv.secret("secret/b"); got != nil {
+		t.Errorf("secret/b written after the interrupt: %v", got)
+	}
+}
+
+func TestCreateSecretsForcedVersionSkipsMountList(t *testing.T) {
+	fv, client := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+	fv.denyMounts = true
+
+	secrets := map[string]map[string]interface{}{"secret/app": {"password": "v2"}}
+	if _, err := CreateSecrets(context.Background(), client, secrets, CreateOptions{}); err == nil {
+		t.Fatal("CreateSecrets succeeded without access to sys/mounts and no forced KV version")
+	}
+
+	result, err := CreateSecrets(context.Background(), client, secrets, CreateOptions{KVVersion: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"secret/app"}; !reflect.DeepEqual(result.Written, want) {
+		t.Errorf("Written = %v, want %v", result.Written, want)
+	}
+	if got := fv.secret("secret/app"); !reflect.DeepEqual(got, secrets["secret/app"]) {
+		t.Errorf("secret/app = %v after the write, want %v", got, secrets["secret/app"])
+	}
+}
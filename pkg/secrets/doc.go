@@ -0,0 +1,35 @@
+/*
+Package secrets implements vaultx's KV operations as a library, independent of the CLI.
+
+Every function takes an explicit *vault.Client (or a source and target client for copies)
+and, where there are several knobs, an options struct, so other Go programs can embed the
+same behavior the vaultx commands expose:
+
+  - GetSecretEngines, LookupKVMount and FindMountForSecret discover KV mounts and their versions
+  - NormalizeMount gives a mount path its canonical form, and RelativeSecretPath strips the
+    mount from a full secret path
+  - ReadSecret, WriteSecret and DeleteSecret read, write and delete a single secret in the
+    mount's KV format, DestroySecret removes one with all its versions, and TouchSecret
+    rewrites a KV v2 secret unchanged as a new version
+  - ReadSecretWrapped and UnwrapSecret read a secret through a response-wrapping token
+  - WalkSecrets and ListSecrets traverse a mount, detecting its KV version first; the
+    traversal itself lives in internal/kvwalk so every operation walks mounts the same way
+  - ExportSecrets reads every secret under a mount
+  - ReadVersionState reads a KV v2 secret's current version and deletion state
+  - DuplicateIndex and FieldIndex summarize secrets read by other operations: groups of
+    identical secrets, and how often each field name is used
+  - DereferenceSecret resolves references from one secret's fields to another's
+  - CreateSecrets writes a set of secrets, routing each to its mount
+  - CopySecrets copies a mount, or part of one, between Vault instances; PlanCopy and
+    ExecuteCopyPlan split it into a read-only planning phase and the copy itself
+
+Progress and per-secret failures are logged through log/slog's default logger, and bulk
+operations also report each secret's outcome in a result (CreateResult, CopyResult). Secret
+values read or written are registered with the vaultx logging package so they are redacted
+from log output.
+
+The commands under cmd/secrets are thin wrappers that translate flags into calls to this
+package.
+*/
+
+package secrets
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"sort"
+	"sync"
+)
+
+// DuplicateIndex groups secrets that hold identical data, so copy-paste sprawl can be
+// found and cleaned up. Secrets are compared by DataChecksum, so key order doesn't
+// matter but every key and value must match. Pass the same DuplicateIndex to several
+// CopySecrets calls to find duplicates across mounts; it is safe for concurrent use.
+type DuplicateIndex struct {
+	mu    sync.Mutex
+	paths map[string][]string
+}
+
+// Add records that secretPath holds data. Empty secrets are ignored, since they carry
+// nothing worth deduplicating.
+func (d *DuplicateIndex) Add(secretPath string, data map[string]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	sum, err := DataChecksum(data)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.paths == nil {
+		d.paths = make(map[string][]string)
+	}
+	d.paths[sum] = append(d.paths[sum], secretPath)
+	return nil
+}
+
+// Groups returns every set of two or more paths holding identical data. Paths within a
+// group are sorted, and groups are sorted by their first path.
+func (d *DuplicateIndex) Groups() [][]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var groups [][]string
+	for _, paths := range d.paths {
+		if len(paths) < 2 {
+			continue
+		}
+		group := append([]string(nil), paths...)
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
@@ -0,0 +1,19 @@
+package secrets
+
+import (
+	"github.com/razahuss02/vaultx/internal/kvwalk"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+)
+
+// Errors returned by this package, re-exported from vaultx's internal packages so
+// callers outside the module can match them with errors.Is.
+var (
+	ErrMountNotFound        = vaultclient.ErrMountNotFound
+	ErrNotKVMount           = vaultclient.ErrNotKVMount
+	ErrMountConflict        = vaultclient.ErrMountConflict
+	ErrMountUpgrading       = vaultclient.ErrMountUpgrading
+	ErrUnsupportedKVVersion = vaultclient.ErrUnsupportedKVVersion
+	ErrVaultSealed          = vaultclient.ErrVaultSealed
+	ErrVaultNotInitialized  = vaultclient.ErrVaultNotInitialized
+	ErrSecretLimit          = kvwalk.ErrSecretLimit
+)
@@ -0,0 +1,30 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault-client-go"
+)
+
+// ExportSecrets reads every secret selected by opts and calls fn with its full path and
+// data, one secret at a time, so callers can write secrets out without holding the whole
+// mount in memory. It stops at the first read error or error returned by fn.
+func ExportSecrets(ctx context.Context, client *vault.Client, opts WalkOptions, fn func(secretPath string, data map[string]interface{}) error) error {
+	if opts.KVVersion == "" {
+		mountInfo, err := LookupKVMount(ctx, client, opts.Mount)
+		if err != nil {
+			return err
+		}
+		opts.KVVersion = mountInfo.Version
+	}
+	mountInfo := MountInfo{MountPath: opts.Mount, Version: opts.KVVersion}
+
+	return WalkSecrets(ctx, client, opts, func(secretPath string) error {
+		data, err := ReadSecret(ctx, client, nil, mountInfo, RelativeSecretPath(opts.Mount, secretPath))
+		if err != nil {
+			return fmt.Errorf("failed to read secret %q: %w", secretPath, err)
+		}
+		return fn(secretPath, data)
+	})
+}
@@ -0,0 +1,219 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault-client-go"
+)
+
+// fakeVault is an in-memory Vault serving the requests the copy and create code makes:
+// health and seal status, the mount list, token lookups and capabilities, and reads,
+// writes and LISTs on KV v1 and v2 mounts.
+type fakeVault struct {
+	t      *testing.T
+	mounts map[string]string // mount path, without slashes, to KV version
+	// capabilities are those the token holds on every path; nil means "root"
+	capabilities []string
+	// denyMounts answers reads of sys/mounts with 403, as for a token without access
+	denyMounts bool
+
+	mu sync.Mutex
+	// secrets maps each secret's full path, including the mount, to its data
+	secrets map[string]map[string]interface{}
+	// failWrites lists full paths whose writes are answered with 500
+	failWrites map[string]bool
+	// onWrite, if not nil, is called with the full path of each secret stored
+	onWrite func(secretPath string)
+	// lists counts the LIST requests served
+	lists int
+}
+
+// newFakeVault starts a fakeVault with the given mounts and secrets and returns it along
+// with a client for it.
+func newFakeVault(t *testing.T, mounts map[string]string, secrets map[string]map[string]interface{}) (*fakeVault, *vault.Client) {
+	t.Helper()
+
+	fv := &fakeVault{t: t, mounts: mounts, secrets: map[string]map[string]interface{}{}, failWrites: map[string]bool{}}
+	for secretPath, data := range secrets {
+		fv.secrets[secretPath] = data
+	}
+	srv := httptest.NewServer(http.HandlerFunc(fv.serve))
+	t.Cleanup(srv.Close)
+
+	// the client's own retries are turned off, so failures are reported at once
+	client, err := vault.New(vault.WithAddress(srv.URL), vault.WithRetryConfiguration(vault.RetryConfiguration{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetToken("test-token"); err != nil {
+		t.Fatal(err)
+	}
+	return fv, client
+}
+
+// secret returns the data stored at the full path secretPath, or nil.
+func (fv *fakeVault) secret(secretPath string) map[string]interface{} {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+	return fv.secrets[secretPath]
+}
+
+func (fv *fakeVault) serve(w http.ResponseWriter, r *http.Request) {
+	urlPath := strings.TrimPrefix(r.URL.Path, "/v1/")
+	switch urlPath {
+	case "sys/seal-status":
+		fmt.Fprint(w, `{"sealed":false}`)
+		return
+	case "sys/health":
+		fmt.Fprint(w, `{"initialized":true,"sealed":false,"standby":false,"version":"1.15.0"}`)
+		return
+	case "auth/token/lookup-self":
+		fmt.Fprint(w, `{"data":{"ttl":0,"policies":["root"]}}`)
+		return
+	case "sys/mounts":
+		if fv.denyMounts {
+			writeJSON(w, http.StatusForbidden, map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+		mounts := map[string]interface{}{}
+		for mount, version := range fv.mounts {
+			mounts[mount+"/"] = map[string]interface{}{"type": "kv", "options": map[string]interface{}{"version": version}}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": mounts})
+		return
+	case "sys/capabilities-self":
+		var req struct {
+			Paths []string `json:"paths"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		granted := fv.capabilities
+		if granted == nil {
+			granted = []string{"root"}
+		}
+		capabilities := map[string]interface{}{}
+		for _, p := range req.Paths {
+			capabilities[p] = granted
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": capabilities})
+		return
+	}
+
+	for mount, version := range fv.mounts {
+		if rest, ok := strings.CutPrefix(urlPath, mount+"/"); ok {
+			fv.serveKV(w, r, mount, version, rest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{"no handler for route"}})
+}
+
+// serveKV answers a request for rest, the URL path within the KV mount.
+func (fv *fakeVault) serveKV(w http.ResponseWriter, r *http.Request, mount, version, rest string) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	if version == "2" {
+		switch {
+		case rest == "config":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+			return
+		case strings.HasPrefix(rest, "metadata/") && r.URL.Query().Get("list") == "true":
+			rest = strings.TrimPrefix(rest, "metadata/")
+		case strings.HasPrefix(rest, "data/"):
+			rest = strings.TrimPrefix(rest, "data/")
+		default:
+			fv.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"unexpected request"}})
+			return
+		}
+	}
+	secretPath := mount + "/" + strings.Trim(rest, "/")
+
+	switch {
+	case r.URL.Query().Get("list") == "true":
+		fv.lists++
+		dir := mount + "/"
+		if relativePath := strings.Trim(rest, "/"); relativePath != "" {
+			dir += relativePath + "/"
+		}
+		keys := fv.list(dir)
+		if len(keys) == 0 {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+
+	case r.Method == http.MethodGet:
+		data, ok := fv.secrets[secretPath]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		if version == "2" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": 1}}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+
+	case r.Method == http.MethodPost || r.Method == http.MethodPut:
+		if fv.failWrites[secretPath] {
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"errors": []string{"internal error"}})
+			return
+		}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		var body map[string]interface{}
+		if err := decoder.Decode(&body); err != nil {
+			fv.t.Errorf("undecodable write to %s: %v", secretPath, err)
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{err.Error()}})
+			return
+		}
+		if version == "2" {
+			body, _ = body["data"].(map[string]interface{})
+		}
+		fv.secrets[secretPath] = body
+		if fv.onWrite != nil {
+			fv.onWrite(secretPath)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"version": 1}})
+
+	default:
+		fv.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"unexpected request"}})
+	}
+}
+
+// list returns the keys directly under dir, a full path ending in a slash, with
+// subdirectories marked by a trailing slash as Vault does.
+func (fv *fakeVault) list(dir string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for secretPath := range fv.secrets {
+		rest, ok := strings.CutPrefix(secretPath, dir)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		if !seen[rest] {
+			seen[rest] = true
+			keys = append(keys, rest)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"sort"
+	"sync"
+)
+
+// FieldCount is how many secrets use one field name.
+type FieldCount struct {
+	Name    string `json:"field"`
+	Secrets int    `json:"secrets"`
+}
+
+// FieldIndex counts the top-level field names used across secrets, so operators can
+// audit schema consistency, e.g. spot secrets missing an "owner" field. Only names are
+// recorded, never values. It is safe for concurrent use.
+type FieldIndex struct {
+	mu      sync.Mutex
+	secrets int
+	counts  map[string]int
+}
+
+// Add records the field names of one secret's data.
+func (f *FieldIndex) Add(data map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	f.secrets++
+	for name := range data {
+		f.counts[name]++
+	}
+}
+
+// Secrets returns how many secrets have been added.
+func (f *FieldIndex) Secrets() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.secrets
+}
+
+// Counts returns every field name seen with the number of secrets using it, most used
+// first and then by name.
+func (f *FieldIndex) Counts() []FieldCount {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make([]FieldCount, 0, len(f.counts))
+	for name, n := range f.counts {
+		counts = append(counts, FieldCount{Name: name, Secrets: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Secrets != counts[j].Secrets {
+			return counts[i].Secrets > counts[j].Secrets
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
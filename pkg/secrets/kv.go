@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// ReadSecret reads the data stored at relativePath under the given mount, using the
+// read endpoint for the mount's KV version. limiter may be nil.
+func ReadSecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string) (map[string]interface{}, error) {
+	mount := NormalizeMount(mountInfo.MountPath)
+
+	switch mountInfo.Version {
+	case "2":
+		var resp *vault.Response[schema.KvV2ReadResponse]
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+			resp, err = client.Secrets.KvV2Read(ctx, relativePath, vault.WithMountPath(mount), opt)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		logging.RegisterSecretValues(resp.Data.Data)
+		return resp.Data.Data, nil
+
+	case "1":
+		var resp *vault.Response[map[string]interface{}]
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+			resp, err = client.Secrets.KvV1Read(ctx, relativePath, vault.WithMountPath(mount), opt)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		logging.RegisterSecretValues(resp.Data)
+		return resp.Data, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, mountInfo.Version)
+	}
+}
+
+// WriteSecret writes data to relativePath under the given mount, formatting the request
+// for the mount's KV version. For KV v2 the newly created version number is returned;
+// for KV v1 it is always 0. limiter may be nil.
+func WriteSecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string, data map[string]interface{}) (int64, error) {
+	return WriteSecretWithOptions(ctx, client, limiter, mountInfo, relativePath, data, nil)
+}
+
+// WriteSecretWithOptions is WriteSecret with KV v2 write options, such as {"cas": 0},
+// sent as the request's options object. KV v1 has no write options, so they are
+// ignored there.
+func WriteSecretWithOptions(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string, data, options map[string]interface{}) (int64, error) {
+	mount := NormalizeMount(mountInfo.MountPath)
+	logging.RegisterSecretValues(data)
+
+	switch mountInfo.Version {
+	case "2":
+		req := schema.KvV2WriteRequest{
+			Data:    data,
+			Options: options,
+		}
+		var resp *vault.Response[schema.KvV2WriteResponse]
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+			resp, err = client.Secrets.KvV2Write(ctx, relativePath, req, vault.WithMountPath(mount), opt)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		return resp.Data.Version, nil
+
+	case "1":
+		return 0, vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			_, err := client.Secrets.KvV1Write(ctx, relativePath, data, vault.WithMountPath(mount), opt)
+			return err
+		})
+
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, mountInfo.Version)
+	}
+}
+
+// DeleteSecret deletes the secret at relativePath under the given mount. On KV v2 this
+// soft-deletes the latest version, which can be undeleted; on KV v1 the secret is removed
+// permanently. limiter may be nil.
+func DeleteSecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string) error {
+	mount := NormalizeMount(mountInfo.MountPath)
+
+	switch mountInfo.Version {
+	case "2":
+		return vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			_, err := client.Secrets.KvV2Delete(ctx, relativePath, vault.WithMountPath(mount), opt)
+			return err
+		})
+
+	case "1":
+		return vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			_, err := client.Secrets.KvV1Delete(ctx, relativePath, vault.WithMountPath(mount), opt)
+			return err
+		})
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, mountInfo.Version)
+	}
+}
+
+// DestroySecret permanently removes the secret at relativePath under the given mount. On
+// KV v2 every version and the secret's metadata are deleted, so nothing can be undeleted;
+// on KV v1, which has no versions, it is the same as DeleteSecret. limiter may be nil.
+func DestroySecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string) error {
+	if mountInfo.Version != "2" {
+		return DeleteSecret(ctx, client, limiter, mountInfo, relativePath)
+	}
+
+	mount := NormalizeMount(mountInfo.MountPath)
+	return vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+		_, err := client.Secrets.KvV2DeleteMetadataAndAllVersions(ctx, relativePath, vault.WithMountPath(mount), opt)
+		return err
+	})
+}
+
+// secretExists reports whether a secret with readable data exists at relativePath under
+// the given mount. A KV v2 secret whose latest version is deleted or destroyed does not
+// count as existing.
+func secretExists(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string) (bool, error) {
+	_, err := ReadSecret(ctx, client, limiter, mountInfo, relativePath)
+	if err == nil {
+		return true, nil
+	}
+	if vault.IsErrorStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	return false, err
+}
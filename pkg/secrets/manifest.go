@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Manifest records a checksum of every secret a copy wrote, as read from the source and
+// read back from the target, as evidence that a migration was faithful. When merging, the
+// source checksum covers the merged data that was written. Pass the same
+// Manifest to several CopySecrets calls to cover a multi-mount run; it is safe for
+// concurrent use.
+type Manifest struct {
+	mu      sync.Mutex
+	entries []ManifestEntry
+}
+
+// ManifestEntry is one copied secret in a Manifest. Paths include the mount.
+type ManifestEntry struct {
+	SourcePath   string `json:"source_path"`
+	TargetPath   string `json:"target_path"`
+	SourceSHA256 string `json:"source_sha256"`
+	TargetSHA256 string `json:"target_sha256"`
+	Match        bool   `json:"match"`
+}
+
+func (m *Manifest) add(entry ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+}
+
+// Entries returns the recorded entries sorted by source path.
+func (m *Manifest) Entries() []ManifestEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := append([]ManifestEntry(nil), m.entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SourcePath < entries[j].SourcePath })
+	return entries
+}
+
+// WriteFile writes the entries as indented JSON to name, readable by the current user
+// only: a checksum of a short or guessable value can reveal it.
+func (m *Manifest) WriteFile(name string) error {
+	out, err := json.MarshalIndent(map[string]interface{}{"secrets": m.Entries()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(out, '\n'), 0o600)
+}
+
+// DataChecksum returns the hex SHA-256 of data's canonical JSON encoding. Map keys are
+// encoded in sorted order at every level, so the checksum does not depend on key order,
+// and numbers decoded by the Vault client keep their exact text.
+func DataChecksum(data map[string]interface{}) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
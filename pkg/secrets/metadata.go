@@ -0,0 +1,154 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// copyMetadataConfig copies a KV v2 secret's per-secret settings (max_versions,
+// cas_required and delete_version_after) from the source to the target.
+//
+// It must run after the secret's data has been written: with cas_required already set
+// on the target, the data writes, which carry no cas parameter, would be rejected.
+func copyMetadataConfig(ctx context.Context, sourceClient, targetClient *vault.Client, limiter *rate.Limiter, sourceMount, targetMount, sourcePath, targetPath string) error {
+	var metadata *vault.Response[schema.KvV2ReadMetadataResponse]
+	err := vaultclient.WithRetry(ctx, sourceClient, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		metadata, err = sourceClient.Secrets.KvV2ReadMetadata(ctx, sourcePath, vault.WithMountPath(sourceMount), opt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	// every setting is sent, even false and zero ones, so the target's are reset to
+	// match rather than left as they were
+	err = writeMetadata(ctx, targetClient, limiter, targetMount, targetPath, map[string]interface{}{
+		"cas_required":         metadata.Data.CasRequired,
+		"delete_version_after": metadata.Data.DeleteVersionAfter,
+		"max_versions":         metadata.Data.MaxVersions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	return nil
+}
+
+// MetaKey is the reserved field of a secret in CreateSecrets input that holds the
+// secret's KV v2 metadata settings rather than data.
+const MetaKey = "_meta"
+
+// SecretMeta is the per-secret KV v2 metadata that a MetaKey block can set. Settings
+// are pointers so that an explicit false or zero, such as cas_required: false or
+// max_versions: 0, is applied rather than mistaken for a setting left out; nil settings
+// keep their current value on the secret.
+type SecretMeta struct {
+	CustomMetadata     map[string]string `json:"custom_metadata,omitempty"`
+	MaxVersions        *int32            `json:"max_versions,omitempty"`
+	CasRequired        *bool             `json:"cas_required,omitempty"`
+	DeleteVersionAfter *string           `json:"delete_version_after,omitempty"`
+
+	// Version, CreatedTime and UpdatedTime record an exported secret's history on the
+	// Vault it came from. Vault sets them itself, so they can't be re-applied; they are
+	// only reported when the secret is created again.
+	Version     int64  `json:"version,omitempty"`
+	CreatedTime string `json:"created_time,omitempty"`
+	UpdatedTime string `json:"updated_time,omitempty"`
+}
+
+// hasSettings reports whether meta sets anything that can be written to Vault, as
+// opposed to only recording the secret's origin.
+func (meta *SecretMeta) hasSettings() bool {
+	return meta.CustomMetadata != nil || meta.MaxVersions != nil || meta.CasRequired != nil || meta.DeleteVersionAfter != nil
+}
+
+// ReadSecretOrigin returns a SecretMeta recording the current version and the creation
+// and last update times of the KV v2 secret at relativePath under mount, for exports
+// that keep the secret's history. limiter may be nil.
+func ReadSecretOrigin(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mount, relativePath string) (*SecretMeta, error) {
+	var metadata *vault.Response[schema.KvV2ReadMetadataResponse]
+	err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		metadata, err = client.Secrets.KvV2ReadMetadata(ctx, relativePath, vault.WithMountPath(NormalizeMount(mount)), opt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretMeta{
+		Version:     metadata.Data.CurrentVersion,
+		CreatedTime: metadata.Data.CreatedTime.UTC().Format(time.RFC3339Nano),
+		UpdatedTime: metadata.Data.UpdatedTime.UTC().Format(time.RFC3339Nano),
+	}, nil
+}
+
+// splitMeta returns a copy of data without its MetaKey block, and the decoded block, or
+// nil if there is none. Unknown settings in the block are rejected, so a typo doesn't
+// silently leave a retention policy unset.
+func splitMeta(data map[string]interface{}) (map[string]interface{}, *SecretMeta, error) {
+	raw, ok := data[MetaKey]
+	if !ok {
+		return data, nil, nil
+	}
+
+	stripped := make(map[string]interface{}, len(data)-1)
+	for key, value := range data {
+		if key != MetaKey {
+			stripped[key] = value
+		}
+	}
+
+	// round-trip through JSON to decode the untyped block into SecretMeta
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+	var meta SecretMeta
+	if err := dec.Decode(&meta); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s block: %w", MetaKey, err)
+	}
+	return stripped, &meta, nil
+}
+
+// writeSecretMeta applies the settings meta sets to the KV v2 secret at relativePath,
+// leaving the others unchanged. Like copyMetadataConfig, it must run after the data has
+// been written.
+func writeSecretMeta(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mount, relativePath string, meta *SecretMeta) error {
+	body := make(map[string]interface{})
+	if meta.CustomMetadata != nil {
+		body["custom_metadata"] = meta.CustomMetadata
+	}
+	if meta.MaxVersions != nil {
+		body["max_versions"] = *meta.MaxVersions
+	}
+	if meta.CasRequired != nil {
+		body["cas_required"] = *meta.CasRequired
+	}
+	if meta.DeleteVersionAfter != nil {
+		body["delete_version_after"] = *meta.DeleteVersionAfter
+	}
+	return writeMetadata(ctx, client, limiter, mount, relativePath, body)
+}
+
+// writeMetadata writes body to the metadata endpoint of the KV v2 secret at
+// relativePath. The client's typed request drops false and zero settings, since its
+// fields are omitempty, so body is sent as is instead; settings it leaves out keep their
+// current value.
+func writeMetadata(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mount, relativePath string, body map[string]interface{}) error {
+	metadataPath := path.Join(NormalizeMount(mount), "metadata", relativePath)
+	return vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+		_, err := client.Write(ctx, metadataPath, body, opt)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+package secrets
+
+import "testing"
+
+func TestSplitMetaKeepsExplicitZeroSettings(t *testing.T) {
+	data, meta, err := splitMeta(map[string]interface{}{
+		"password": "s3cret",
+		MetaKey: map[string]interface{}{
+			"cas_required": false,
+			"max_versions": 0,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := data[MetaKey]; ok {
+		t.Fatalf("%s block left in data: %v", MetaKey, data)
+	}
+	if meta.CasRequired == nil || *meta.CasRequired {
+		t.Errorf("CasRequired = %v, want explicit false", meta.CasRequired)
+	}
+	if meta.MaxVersions == nil || *meta.MaxVersions != 0 {
+		t.Errorf("MaxVersions = %v, want explicit 0", meta.MaxVersions)
+	}
+	if meta.DeleteVersionAfter != nil || meta.CustomMetadata != nil {
+		t.Errorf("unset settings decoded as set: %+v", meta)
+	}
+	if !meta.hasSettings() {
+		t.Error("hasSettings() = false for explicit false and zero settings")
+	}
+}
+
+func TestSplitMetaOriginOnly(t *testing.T) {
+	_, meta, err := splitMeta(map[string]interface{}{
+		MetaKey: map[string]interface{}{"version": 4, "created_time": "2024-01-09T10:00:00Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.hasSettings() {
+		t.Errorf("hasSettings() = true for a block that only records the origin: %+v", meta)
+	}
+}
@@ -0,0 +1,208 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+)
+
+// MountInfo describes a secrets engine mount.
+type MountInfo struct {
+	MountPath string
+	Type      string // engine type, e.g. "kv"
+	Version   string // "1" or "2"
+}
+
+// GetSecretEngines retrieves the enabled KV secret engine mounts from the Vault server
+// and returns a map of mount paths to their associated MountInfo.
+//
+// Mounts of other engine types (transit, pki, ...) are left out, so a secret path can
+// never be routed to them. The version of each KV mount is read from its options; if it
+// is not explicitly set, it is reported as "1", matching Vault's convention for KV mounts
+// created without a version.
+//
+// This function is used to dynamically discover available KV mounts and their versions
+// for secret write operations.
+func GetSecretEngines(ctx context.Context, client *vault.Client) (map[string]MountInfo, error) {
+	all, err := listSecretEngines(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make(map[string]MountInfo)
+	for mountPath, mountInfo := range all {
+		if isKV(mountInfo) {
+			mounts[mountPath] = mountInfo
+		}
+	}
+
+	return mounts, nil
+}
+
+// listSecretEngines returns every enabled secret engine mount, whatever its type.
+func listSecretEngines(ctx context.Context, client *vault.Client) (map[string]MountInfo, error) {
+	resp, err := client.System.MountsListSecretsEngines(ctx)
+	if err != nil {
+		slog.Error("Failed to list secret engines", "error", err)
+		return nil, err
+	}
+
+	mounts := make(map[string]MountInfo)
+	for mountPath, raw := range resp.Data {
+		data, ok := raw.(map[string]interface{})
+		if !ok {
+			slog.Warn("unexpected mount data format", "mountPath", mountPath)
+			continue
+		}
+
+		mountType, _ := data["type"].(string)
+
+		version := ""
+		if options, ok := data["options"].(map[string]interface{}); ok {
+			version = mountVersion(options["version"])
+		}
+
+		// Vault omits options.version on KV v1 mounts, so empty means v1.
+		if version == "" {
+			version = "1"
+		}
+		if isKV(MountInfo{Type: mountType}) && version != "1" && version != "2" {
+			slog.Warn("unrecognized KV mount version, treating mount as KV v1", "mountPath", mountPath, "version", version)
+			version = "1"
+		}
+
+		mounts[mountPath] = MountInfo{
+			MountPath: mountPath,
+			Type:      mountType,
+			Version:   version,
+		}
+	}
+
+	return mounts, nil
+}
+
+// mountVersion normalizes a mount's options.version, which Vault normally returns as a
+// string ("2") but which some servers and proxies encode as a number (2 or 2.0). nil
+// yields "".
+func mountVersion(raw interface{}) string {
+	if raw == nil {
+		return ""
+	}
+	version := strings.TrimSpace(fmt.Sprint(raw))
+	if f, err := strconv.ParseFloat(version, 64); err == nil && f == math.Trunc(f) {
+		version = strconv.FormatInt(int64(f), 10)
+	}
+	return version
+}
+
+// LookupKVMount returns the MountInfo for mount, failing if the mount does not exist or
+// is not a KV engine. The mount may be given with or without a trailing slash.
+//
+// A KV v2 mount still upgrading from v1 is waited on briefly, and reported with
+// ErrMountUpgrading if the upgrade doesn't finish, since its reported version can't be
+// trusted until then.
+func LookupKVMount(ctx context.Context, client *vault.Client, mount string) (MountInfo, error) {
+	mounts, err := listSecretEngines(ctx, client)
+	if err != nil {
+		return MountInfo{}, err
+	}
+
+	mountInfo, ok := mounts[mountKey(mount)]
+	if !ok {
+		return MountInfo{}, fmt.Errorf("%w: %q does not exist", ErrMountNotFound, mount)
+	}
+	if !isKV(mountInfo) {
+		return MountInfo{}, fmt.Errorf("%w: %q is a %q engine", ErrNotKVMount, mount, mountInfo.Type)
+	}
+	if err := waitForKVUpgrade(ctx, client, mountInfo); err != nil {
+		return MountInfo{}, err
+	}
+
+	return mountInfo, nil
+}
+
+// EnableKVMount enables a KV engine of the given version ("1" or "2") at mount. It is
+// idempotent: if a KV mount of that version already exists there, nothing is changed and
+// created is false. A mount of another type or KV version at the same path is an error
+// (ErrNotKVMount or ErrMountConflict).
+func EnableKVMount(ctx context.Context, client *vault.Client, mount, version string) (created bool, err error) {
+	if version != "1" && version != "2" {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, version)
+	}
+
+	mounts, err := listSecretEngines(ctx, client)
+	if err != nil {
+		return false, err
+	}
+	if existing, ok := mounts[mountKey(mount)]; ok {
+		if !isKV(existing) {
+			return false, fmt.Errorf("%w: %q is a %q engine", ErrNotKVMount, mount, existing.Type)
+		}
+		if existing.Version != version {
+			return false, fmt.Errorf("%w: %q is KV v%s", ErrMountConflict, mount, existing.Version)
+		}
+		return false, nil
+	}
+
+	_, err = client.System.MountsEnableSecretsEngine(ctx, NormalizeMount(mount), schema.MountsEnableSecretsEngineRequest{
+		Type:    "kv",
+		Options: map[string]interface{}{"version": version},
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isKV reports whether the mount is a KV engine. "generic" is the legacy name of the KV
+// v1 engine.
+func isKV(mountInfo MountInfo) bool {
+	return mountInfo.Type == "kv" || mountInfo.Type == "generic"
+}
+
+// FindMountForSecret determines the Vault mount that a secret path belongs to
+// and returns the corresponding MountInfo along with the path relative to the mount.
+//
+// It selects the longest matching mount prefix from the available mounts to ensure
+// the most specific match is chosen. This is important when mounts overlap, such as
+// "secrets/" and "secrets/internal/".
+//
+// For example, given a secretPath of "secrets/users/user1" and a mount "secrets/",
+// it will return the MountInfo for "secrets/" and the relative path "users/user1".
+//
+// If no mount is a prefix of secretPath, an error wrapping ErrMountNotFound is returned
+// rather than a zero MountInfo, so the secret can't be routed to an empty mount path.
+func FindMountForSecret(secretPath string, mounts map[string]MountInfo) (MountInfo, string, error) {
+	var bestMatch string
+	for mount := range mounts {
+		if strings.HasPrefix(strings.Trim(secretPath, "/")+"/", mountKey(mount)) && len(mount) > len(bestMatch) {
+			bestMatch = mount
+		}
+	}
+	if bestMatch == "" {
+		return MountInfo{}, "", fmt.Errorf("%w: no KV mount matches secret path %q", ErrMountNotFound, secretPath)
+	}
+
+	relativePath := RelativeSecretPath(bestMatch, secretPath)
+
+	return mounts[bestMatch], relativePath, nil
+}
+
+// AssumeMount returns the MountInfo and relative path for secretPath without asking
+// Vault, taking the path's first segment as a KV mount of the given version. It is how
+// paths are resolved when the KV version is forced, so that tokens unable to read
+// sys/mounts can still be used; a secret under a nested mount such as "secret/team/"
+// needs its mount detected with FindMountForSecret instead.
+func AssumeMount(secretPath, version string) (MountInfo, string, error) {
+	mount, relativePath, _ := strings.Cut(strings.Trim(secretPath, "/"), "/")
+	if mount == "" {
+		return MountInfo{}, "", fmt.Errorf("%w: empty secret path", ErrMountNotFound)
+	}
+	return MountInfo{MountPath: mountKey(mount), Type: "kv", Version: version}, strings.Trim(relativePath, "/"), nil
+}
@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"strings"
+)
+
+// NormalizeMount returns mount in the canonical form every vaultx operation uses: with
+// no leading or trailing slash, so "secret", "secret/" and "/secret/" all become
+// "secret". Mount paths are normalized once, where they enter an operation, so paths
+// joined from them never carry a missing or doubled slash.
+func NormalizeMount(mount string) string {
+	return strings.Trim(mount, "/")
+}
+
+// mountKey returns mount in the form Vault keys its mounts by in sys/mounts: normalized,
+// with a single trailing slash.
+func mountKey(mount string) string {
+	return NormalizeMount(mount) + "/"
+}
+
+// RelativeSecretPath returns secretPath relative to the given mount.
+//
+// Mounts are matched on whole path segments, so the mount may be given with or
+// without leading/trailing slashes ("secret", "secret/", "/secret/" are equivalent)
+// and "secret" never matches "secrets/app". The returned path never has a leading
+// or trailing slash. If secretPath is not under mount it is returned trimmed but
+// otherwise unchanged.
+//
+// For example, RelativeSecretPath("secret/", "secret/app/db") returns "app/db".
+func RelativeSecretPath(mount, secretPath string) string {
+	mount = NormalizeMount(mount)
+	secretPath = strings.Trim(secretPath, "/")
+
+	if mount == "" {
+		return secretPath
+	}
+	if secretPath == mount {
+		return ""
+	}
+	if strings.HasPrefix(secretPath, mount+"/") {
+		// a doubled slash after the mount ("secret//app") must not leave a leading one
+		return strings.TrimLeft(strings.TrimPrefix(secretPath, mount+"/"), "/")
+	}
+
+	return secretPath
+}
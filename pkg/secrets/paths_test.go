@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeMount(t *testing.T) {
+	tests := []struct {
+		mount, want string
+	}{
+		{"secret", "secret"},
+		{"secret/", "secret"},
+		{"/secret", "secret"},
+		{"/secret/", "secret"},
+		{"//secret//", "secret"},
+		{"team/secret/", "team/secret"},
+		{"/", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeMount(tt.mount); got != tt.want {
+			t.Errorf("NormalizeMount(%q) = %q, want %q", tt.mount, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeSecretPathSlashes(t *testing.T) {
+	mounts := []string{"secret", "secret/", "/secret", "/secret/", "//secret//"}
+	paths := []string{"secret/app/db", "/secret/app/db", "secret/app/db/", "/secret/app/db/", "//secret/app/db//", "secret//app/db"}
+	for _, mount := range mounts {
+		for _, secretPath := range paths {
+			if got := RelativeSecretPath(mount, secretPath); got != "app/db" {
+				t.Errorf("RelativeSecretPath(%q, %q) = %q, want %q", mount, secretPath, got, "app/db")
+			}
+		}
+	}
+
+	for _, mount := range []string{"", "/", "//"} {
+		if got := RelativeSecretPath(mount, "/secret/app/db/"); got != "secret/app/db" {
+			t.Errorf("RelativeSecretPath(%q, %q) = %q, want the trimmed path", mount, "/secret/app/db/", got)
+		}
+	}
+	for _, secretPath := range []string{"", "/", "//"} {
+		if got := RelativeSecretPath("secret", secretPath); got != "" {
+			t.Errorf("RelativeSecretPath(%q, %q) = %q, want %q", "secret", secretPath, got, "")
+		}
+	}
+}
+
+func TestRelativeSecretPathEdgeCases(t *testing.T) {
+	tests := []struct {
+		name, mount, secretPath, want string
+	}{
+		{"mount equal to path", "secret", "secret", ""},
+		{"mount equal to path with slashes", "secret/", "/secret/", ""},
+		{"trailing slash on a directory", "secret", "secret/app/", "app"},
+		{"nested mount", "team/secret", "team/secret/app/db", "app/db"},
+		{"nested mount with slashes", "/team/secret/", "team/secret/app/db/", "app/db"},
+		{"parent of a nested mount", "team", "team/secret/app", "secret/app"},
+		{"mount is only a name prefix", "secret", "secrets/app", "secrets/app"},
+		{"path outside the mount", "secret", "other/app", "other/app"},
+		{"mount as a later segment", "app", "secret/app/db", "secret/app/db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RelativeSecretPath(tt.mount, tt.secretPath); got != tt.want {
+				t.Fatalf("RelativeSecretPath(%q, %q) = %q, want %q", tt.mount, tt.secretPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMountForSecret(t *testing.T) {
+	mounts := map[string]MountInfo{
+		"secret/":          {MountPath: "secret/", Version: "2"},
+		"secret/internal/": {MountPath: "secret/internal/", Version: "1"},
+		"secrets/":         {MountPath: "secrets/", Version: "2"},
+	}
+	tests := []struct {
+		secretPath, wantMount, wantRelative string
+	}{
+		{"secret/app/db", "secret/", "app/db"},
+		{"/secret/app/db/", "secret/", "app/db"},
+		{"secret/internal/db", "secret/internal/", "db"},
+		{"secret/internals/db", "secret/", "internals/db"},
+		{"secrets/app", "secrets/", "app"},
+		{"secret", "secret/", ""},
+		{"secret/internal", "secret/internal/", ""},
+	}
+	for _, tt := range tests {
+		mountInfo, relativePath, err := FindMountForSecret(tt.secretPath, mounts)
+		if err != nil {
+			t.Errorf("FindMountForSecret(%q) failed: %v", tt.secretPath, err)
+			continue
+		}
+		if mountInfo.MountPath != tt.wantMount || relativePath != tt.wantRelative {
+			t.Errorf("FindMountForSecret(%q) = %q, %q, want %q, %q", tt.secretPath, mountInfo.MountPath, relativePath, tt.wantMount, tt.wantRelative)
+		}
+	}
+
+	for _, secretPath := range []string{"other/app", "secre/app", ""} {
+		if _, _, err := FindMountForSecret(secretPath, mounts); !errors.Is(err, ErrMountNotFound) {
+			t.Errorf("FindMountForSecret(%q) error = %v, want ErrMountNotFound", secretPath, err)
+		}
+	}
+}
+
+func TestAssumeMount(t *testing.T) {
+	tests := []struct {
+		secretPath, wantMount, wantRelative string
+	}{
+		{"secret/app/db", "secret/", "app/db"},
+		{"/secret/app/db/", "secret/", "app/db"},
+		{"secret/internal/db", "secret/", "internal/db"},
+		{"secret", "secret/", ""},
+	}
+	for _, tt := range tests {
+		mountInfo, relativePath, err := AssumeMount(tt.secretPath, "1")
+		if err != nil {
+			t.Errorf("AssumeMount(%q) failed: %v", tt.secretPath, err)
+			continue
+		}
+		if mountInfo.MountPath != tt.wantMount || mountInfo.Version != "1" || relativePath != tt.wantRelative {
+			t.Errorf("AssumeMount(%q) = %q (KV v%s), %q, want %q (KV v1), %q", tt.secretPath, mountInfo.MountPath, mountInfo.Version, relativePath, tt.wantMount, tt.wantRelative)
+		}
+	}
+
+	if _, _, err := AssumeMount("/", "1"); !errors.Is(err, ErrMountNotFound) {
+		t.Errorf("AssumeMount(%q) error = %v, want ErrMountNotFound", "/", err)
+	}
+}
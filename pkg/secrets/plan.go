@@ -0,0 +1,264 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+)
+
+// CopyPlan is the work one CopySecrets run will do, resolved before anything is
+// written: the mounts and KV versions involved, which version-dependent options take
+// effect, and every secret to copy with its target path. It can be printed or saved
+// for review, and is carried out by ExecuteCopyPlan.
+type CopyPlan struct {
+	SourceMount   string `json:"source_mount"`
+	TargetMount   string `json:"target_mount"`
+	SourceVersion string `json:"source_kv_version"`
+	TargetVersion string `json:"target_kv_version"`
+	// CreateTargetMount is set when the target mount doesn't exist yet and will be
+	// enabled as a KV engine of TargetVersion before copying.
+	CreateTargetMount bool `json:"create_target_mount,omitempty"`
+	// AllVersions and WithMetadataConfig report whether the options of the same name
+	// take effect, which depends on both mounts being KV v2.
+	AllVersions        bool `json:"all_versions"`
+	WithMetadataConfig bool `json:"with_metadata_config"`
+	// PinnedVersion is the KV v2 version copied of each secret instead of the latest,
+	// or 0.
+	PinnedVersion int            `json:"pinned_version,omitempty"`
+	Items         []CopyPlanItem `json:"items"`
+}
+
+// CopyPlanItem is one secret in a CopyPlan.
+type CopyPlanItem struct {
+	SourcePath string `json:"source_path"` // full path, including the source mount
+	TargetPath string `json:"target_path"` // path within the target mount
+}
+
+// PlanCopy builds the CopyPlan for copying with opts, reading from both Vaults but
+// writing nothing. It detects the mounts' KV versions unless opts forces them, and lists
+// the source secrets selected by opts.MapOnly, opts.Paths, opts.SourcePath, opts.Path or
+// opts.Prefix. With opts.Preflight both instances and tokens are verified before the
+// source is listed.
+// Target paths are rewritten per opts.PathMap; a plan that would copy two secrets to the
+// same target path, or more than opts.MaxSecrets secrets, is rejected.
+func PlanCopy(ctx context.Context, sourceClient, targetClient *vault.Client, opts CopyOptions) (*CopyPlan, error) {
+	sourceMount := NormalizeMount(opts.SourceMount)
+	targetMount := NormalizeMount(opts.TargetMount)
+	targetPrefix := strings.Trim(opts.TargetPrefix, "/")
+
+	if err := vaultclient.CheckSealed(ctx, sourceClient, "source"); err != nil {
+		return nil, err
+	}
+	if err := vaultclient.CheckSealed(ctx, targetClient, "target"); err != nil {
+		return nil, err
+	}
+
+	kvVersion := opts.KVVersion
+	if kvVersion == "" {
+		sourceInfo, err := LookupKVMount(ctx, sourceClient, sourceMount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect source mount version: %w", err)
+		}
+		kvVersion = sourceInfo.Version
+	}
+	if kvVersion != "1" && kvVersion != "2" {
+		slog.Error("unsupported KV version", "version", kvVersion)
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, kvVersion)
+	}
+
+	plan := &CopyPlan{
+		SourceMount:   sourceMount,
+		TargetMount:   targetMount,
+		SourceVersion: kvVersion,
+	}
+
+	targetVersion := opts.TargetKVVersion
+	if targetVersion == "" {
+		targetVersion = opts.KVVersion
+	}
+	if targetVersion == "" || opts.CreateTargetMount {
+		targetInfo, err := LookupKVMount(ctx, targetClient, targetMount)
+		switch {
+		case err == nil && targetVersion == "":
+			targetVersion = targetInfo.Version
+		case errors.Is(err, ErrMountNotFound) && opts.CreateTargetMount:
+			plan.CreateTargetMount = true
+			if targetVersion == "" {
+				targetVersion = kvVersion
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to detect target mount version: %w", err)
+		}
+	}
+	if targetVersion != "1" && targetVersion != "2" {
+		slog.Error("unsupported target KV version", "version", targetVersion)
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, targetVersion)
+	}
+	plan.TargetVersion = targetVersion
+	if targetVersion != kvVersion {
+		slog.Info("translating secrets between KV versions", "source_version", kvVersion, "target_version", targetVersion)
+	}
+
+	// the capability paths depend on the KV versions, so preflight waits for them, but it
+	// runs before the source is listed or the target mount created so that a token
+	// lacking access gets its explanation instead of the first failed request
+	if opts.Preflight {
+		prefix := opts.Prefix
+		if strings.HasSuffix(opts.Path, "/") {
+			prefix = opts.Path
+		}
+		if opts.SourcePath != "" {
+			prefix = opts.SourcePath
+		}
+		checks := []preflightTarget{
+			{
+				Name:     "source",
+				Client:   sourceClient,
+				Paths:    []string{kvCapabilityPath(sourceMount, kvVersion, "list", prefix), kvCapabilityPath(sourceMount, kvVersion, "read", prefix)},
+				Required: []string{"read", "list"},
+			},
+			{
+				Name:     "target",
+				Client:   targetClient,
+				Paths:    []string{kvCapabilityPath(targetMount, targetVersion, "write", targetPrefix)},
+				Required: []string{"create", "update"},
+			},
+		}
+		if err := runPreflight(ctx, checks); err != nil {
+			return nil, fmt.Errorf("preflight check failed: %w", err)
+		}
+	}
+
+	if opts.SourceVersion < 0 {
+		return nil, fmt.Errorf("invalid source version %d", opts.SourceVersion)
+	}
+	if opts.SourceVersion > 0 && kvVersion != "2" {
+		return nil, fmt.Errorf("copying a pinned source version requires a KV v2 source mount, %q is KV v%s", sourceMount, kvVersion)
+	}
+	plan.PinnedVersion = opts.SourceVersion
+
+	allVersions := opts.AllVersions
+	if allVersions && opts.SourceVersion > 0 {
+		slog.Warn("AllVersions replays history and cannot pin a version, copying the SourceVersion only", "version", opts.SourceVersion)
+		allVersions = false
+	}
+	if allVersions && opts.Merge {
+		slog.Warn("AllVersions replays history and cannot merge, copying latest values only")
+		allVersions = false
+	}
+	if allVersions && opts.Dereference {
+		slog.Warn("AllVersions replays history and cannot dereference, copying latest values only")
+		allVersions = false
+	}
+	if allVersions && (kvVersion != "2" || targetVersion != "2") {
+		slog.Warn("AllVersions requires KV v2 on both mounts, copying latest values only", "source_version", kvVersion, "target_version", targetVersion)
+		allVersions = false
+	}
+	plan.AllVersions = allVersions
+
+	withMetadataConfig := opts.WithMetadataConfig
+	if withMetadataConfig && (kvVersion != "2" || targetVersion != "2") {
+		slog.Warn("WithMetadataConfig requires KV v2 on both mounts, ignoring it", "source_version", kvVersion, "target_version", targetVersion)
+		withMetadataConfig = false
+	}
+	plan.WithMetadataConfig = withMetadataConfig
+
+	pathMap := make(map[string]string, len(opts.PathMap))
+	for source, target := range opts.PathMap {
+		pathMap[strings.Trim(source, "/")] = strings.Trim(target, "/")
+	}
+
+	var secretsList []string
+	switch {
+	case opts.MapOnly:
+		// entries for other mounts belong to other runs
+		for source := range pathMap {
+			if strings.HasPrefix(source, sourceMount+"/") {
+				secretsList = append(secretsList, source)
+			}
+		}
+		sort.Strings(secretsList)
+	case len(opts.Paths) > 0:
+		for _, secretPath := range opts.Paths {
+			secretsList = append(secretsList, path.Join(sourceMount, strings.Trim(secretPath, "/")))
+		}
+	case opts.Path != "" && !strings.HasSuffix(opts.Path, "/"):
+		secretsList = []string{path.Join(sourceMount, strings.Trim(opts.Path, "/"))}
+	default:
+		prefix := opts.Prefix
+		if opts.Path != "" {
+			prefix = opts.Path
+		}
+		if opts.SourcePath != "" {
+			prefix = opts.SourcePath
+		}
+		var err error
+		secretsList, err = ListSecrets(ctx, sourceClient, WalkOptions{Mount: sourceMount, Prefix: prefix, MaxDepth: opts.MaxDepth, MaxSecrets: opts.MaxSecrets, KVVersion: kvVersion, Limiter: newRateLimiter(opts.RateLimit)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets under source mount: %w", err)
+		}
+	}
+	if opts.MaxSecrets > 0 && len(secretsList) > opts.MaxSecrets {
+		return nil, fmt.Errorf("%w: %d secrets selected, more than %d", ErrSecretLimit, len(secretsList), opts.MaxSecrets)
+	}
+
+	// with a source path, its subtree is copied as if it were the root of the mount
+	sourcePath := strings.Trim(opts.SourcePath, "/")
+	plan.Items = make([]CopyPlanItem, 0, len(secretsList))
+	mapped := make(map[string]bool)
+	for _, fullPath := range secretsList {
+		if target, ok := pathMap[fullPath]; ok {
+			plan.Items = append(plan.Items, CopyPlanItem{SourcePath: fullPath, TargetPath: target})
+			mapped[fullPath] = true
+			continue
+		}
+		if opts.MapOnly {
+			continue
+		}
+
+		relativePath := RelativeSecretPath(sourceMount, fullPath)
+		if sourcePath != "" && len(opts.Paths) == 0 {
+			relativePath = strings.TrimPrefix(relativePath, sourcePath+"/")
+		}
+		plan.Items = append(plan.Items, CopyPlanItem{
+			SourcePath: fullPath,
+			TargetPath: path.Join(targetPrefix, relativePath),
+		})
+	}
+
+	if resumeFrom := strings.Trim(opts.ResumeFrom, "/"); resumeFrom != "" {
+		sort.Slice(plan.Items, func(i, j int) bool { return plan.Items[i].SourcePath < plan.Items[j].SourcePath })
+		first := sort.Search(len(plan.Items), func(i int) bool {
+			return RelativeSecretPath(sourceMount, plan.Items[i].SourcePath) >= resumeFrom
+		})
+		slog.Info("resuming copy, skipping secrets sorted before the anchor", "resume_from", resumeFrom, "skipped", first)
+		plan.Items = plan.Items[first:]
+	}
+
+	// entries for other mounts belong to other runs; those for this one that matched
+	// nothing are most likely typos
+	for source := range pathMap {
+		if strings.HasPrefix(source, sourceMount+"/") && !mapped[source] {
+			slog.Warn("secret in path map not found on source mount, ignoring it", "path", source)
+		}
+	}
+
+	// a map can send two secrets to one path, where the second would silently replace
+	// or be merged into the first
+	targets := make(map[string]string, len(plan.Items))
+	for _, item := range plan.Items {
+		if other, ok := targets[item.TargetPath]; ok {
+			return nil, fmt.Errorf("secrets %q and %q would both be copied to %q", other, item.SourcePath, item.TargetPath)
+		}
+		targets[item.TargetPath] = item.SourcePath
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,207 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var planSource = map[string]map[string]interface{}{
+	"secret/app/db":          {"password": "db"},
+	"secret/app/nested/deep": {"password": "deep"},
+	"secret/top":             {"password": "top"},
+	"other/app/db":           {"password": "other"},
+}
+
+// planItems returns plan's items as source path to target path.
+func planItems(plan *CopyPlan) map[string]string {
+	items := make(map[string]string, len(plan.Items))
+	for _, item := range plan.Items {
+		items[item.SourcePath] = item.TargetPath
+	}
+	return items
+}
+
+func TestPlanCopyTargetPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CopyOptions
+		want map[string]string
+	}{
+		{
+			name: "whole mount",
+			want: map[string]string{"secret/app/db": "app/db", "secret/app/nested/deep": "app/nested/deep", "secret/top": "top"},
+		},
+		{
+			name: "target prefix",
+			opts: CopyOptions{TargetPrefix: "/copied/"},
+			want: map[string]string{"secret/app/db": "copied/app/db", "secret/app/nested/deep": "copied/app/nested/deep", "secret/top": "copied/top"},
+		},
+		{
+			name: "source path copied as the root",
+			opts: CopyOptions{SourcePath: "app/", TargetPrefix: "moved"},
+			want: map[string]string{"secret/app/db": "moved/db", "secret/app/nested/deep": "moved/nested/deep"},
+		},
+		{
+			name: "path map",
+			opts: CopyOptions{PathMap: map[string]string{"/secret/app/db/": "/database/primary/", "other/app/db": "ignored"}},
+			want: map[string]string{"secret/app/db": "database/primary", "secret/app/nested/deep": "app/nested/deep", "secret/top": "top"},
+		},
+		{
+			name: "path map takes precedence over the target prefix",
+			opts: CopyOptions{TargetPrefix: "copied", PathMap: map[string]string{"secret/top": "root"}},
+			want: map[string]string{"secret/app/db": "copied/app/db", "secret/app/nested/deep": "copied/app/nested/deep", "secret/top": "root"},
+		},
+		{
+			name: "map only",
+			opts: CopyOptions{MapOnly: true, PathMap: map[string]string{"secret/top": "root", "other/app/db": "ignored"}},
+			want: map[string]string{"secret/top": "root"},
+		},
+		{
+			name: "listed paths",
+			opts: CopyOptions{Paths: []string{"/app/db", "top/"}, TargetPrefix: "copied"},
+			want: map[string]string{"secret/app/db": "copied/app/db", "secret/top": "copied/top"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, source := newFakeVault(t, map[string]string{"secret": "2", "other": "2"}, planSource)
+			_, target := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+			tt.opts.SourceMount, tt.opts.TargetMount = "secret", "secret"
+			plan, err := PlanCopy(context.Background(), source, target, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := planItems(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanCopyFiltering(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CopyOptions
+		want []string
+	}{
+		{
+			name: "prefix",
+			opts: CopyOptions{Prefix: "app/"},
+			want: []string{"secret/app/db", "secret/app/nested/deep"},
+		},
+		{
+			name: "single path",
+			opts: CopyOptions{Path: "app/db"},
+			want: []string{"secret/app/db"},
+		},
+		{
+			name: "path ending in a slash is a prefix",
+			opts: CopyOptions{Path: "app/nested/"},
+			want: []string{"secret/app/nested/deep"},
+		},
+		{
+			name: "max depth",
+			opts: CopyOptions{MaxDepth: 2},
+			want: []string{"secret/app/db", "secret/top"},
+		},
+		{
+			name: "resume from",
+			opts: CopyOptions{ResumeFrom: "/app/nested/"},
+			want: []string{"secret/app/nested/deep", "secret/top"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, source := newFakeVault(t, map[string]string{"secret": "2"}, planSource)
+			_, target := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+			tt.opts.SourceMount, tt.opts.TargetMount = "secret", "secret"
+			plan, err := PlanCopy(context.Background(), source, target, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, item := range plan.Items {
+				got = append(got, item.SourcePath)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("planned %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanCopyRejectsDuplicateTargets(t *testing.T) {
+	_, source := newFakeVault(t, map[string]string{"secret": "2"}, planSource)
+	_, target := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+	_, err := PlanCopy(context.Background(), source, target, CopyOptions{
+		SourceMount: "secret",
+		TargetMount: "secret",
+		PathMap:     map[string]string{"secret/app/db": "top"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `would both be copied to "top"`) {
+		t.Fatalf("err = %v, want secret/app/db and secret/top rejected as copied to the same path", err)
+	}
+}
+
+func TestPlanCopyMaxSecrets(t *testing.T) {
+	_, source := newFakeVault(t, map[string]string{"secret": "2"}, planSource)
+	_, target := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+	opts := CopyOptions{SourceMount: "secret", TargetMount: "secret", MaxSecrets: 2}
+	if _, err := PlanCopy(context.Background(), source, target, opts); !errors.Is(err, ErrSecretLimit) {
+		t.Fatalf("err = %v, want ErrSecretLimit", err)
+	}
+
+	opts.Paths = []string{"app/db", "top", "app/nested/deep"}
+	if _, err := PlanCopy(context.Background(), source, target, opts); !errors.Is(err, ErrSecretLimit) {
+		t.Fatalf("err = %v for listed paths, want ErrSecretLimit", err)
+	}
+}
+
+func TestPlanCopyVersions(t *testing.T) {
+	_, source := newFakeVault(t, map[string]string{"secret": "2"}, planSource)
+	_, target := newFakeVault(t, map[string]string{"kv": "1"}, nil)
+
+	plan, err := PlanCopy(context.Background(), source, target, CopyOptions{SourceMount: "secret", TargetMount: "kv", AllVersions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.SourceVersion != "2" || plan.TargetVersion != "1" {
+		t.Errorf("versions = %s to %s, want 2 to 1", plan.SourceVersion, plan.TargetVersion)
+	}
+	if plan.AllVersions {
+		t.Error("AllVersions kept for a KV v1 target")
+	}
+
+	plan, err = PlanCopy(context.Background(), source, target, CopyOptions{SourceMount: "secret", TargetMount: "new", CreateTargetMount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.CreateTargetMount || plan.TargetVersion != "2" {
+		t.Errorf("missing target mount planned as CreateTargetMount=%v, KV v%s; want true, v2", plan.CreateTargetMount, plan.TargetVersion)
+	}
+
+	if _, err := PlanCopy(context.Background(), source, target, CopyOptions{SourceMount: "secret", TargetMount: "new"}); !errors.Is(err, ErrMountNotFound) {
+		t.Fatalf("err = %v for a missing target mount, want ErrMountNotFound", err)
+	}
+}
+
+func TestPlanCopyPreflightRunsBeforeListing(t *testing.T) {
+	sourceVault, source := newFakeVault(t, map[string]string{"secret": "2"}, planSource)
+	sourceVault.capabilities = []string{"read"}
+	_, target := newFakeVault(t, map[string]string{"secret": "2"}, nil)
+
+	_, err := PlanCopy(context.Background(), source, target, CopyOptions{SourceMount: "secret", TargetMount: "secret", Preflight: true})
+	if err == nil || !strings.Contains(err.Error(), "source: token lacks list capability") {
+		t.Fatalf("err = %v, want the preflight to report the missing list capability", err)
+	}
+	if sourceVault.lists != 0 {
+		t.Fatalf("source listed %d times before the preflight failed", sourceVault.lists)
+	}
+}
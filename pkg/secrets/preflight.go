@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+)
+
+// preflightTarget describes one Vault instance to verify before an operation and the
+// capabilities its token needs on the given paths.
+type preflightTarget struct {
+	Name     string
+	Client   *vault.Client
+	Paths    []string
+	Required []string
+}
+
+// runPreflight verifies each target is reachable, initialized and unsealed (standby
+// nodes are fine), that its token is valid, and that the token holds the required
+// capabilities. It stops at the first failure and returns an error describing how to
+// fix it.
+func runPreflight(ctx context.Context, targets []preflightTarget) error {
+	for _, t := range targets {
+		if _, err := vaultclient.CheckHealth(ctx, t.Client, t.Name); err != nil {
+			return err
+		}
+		token, err := vaultclient.LookupToken(ctx, t.Client, t.Name)
+		if err != nil {
+			return err
+		}
+		if token.TTL > 0 && token.TTL < vaultclient.MinTokenTTL {
+			slog.Warn("token expires soon; the operation may not finish before it does", "vault", t.Name, "ttl", token.TTL)
+		}
+
+		missing, err := missingCapabilities(ctx, t.Client, t.Paths, t.Required)
+		if err != nil {
+			return fmt.Errorf("%s: unable to query token capabilities: %w", t.Name, err)
+		}
+		for _, p := range t.Paths {
+			if caps := missing[p]; len(caps) > 0 {
+				return fmt.Errorf("%s: token lacks %s capability on %q; update the token's policies", t.Name, strings.Join(caps, "/"), p)
+			}
+		}
+
+		slog.Info("preflight passed", "vault", t.Name)
+	}
+	return nil
+}
+
+// missingCapabilities queries sys/capabilities-self for paths and returns, per path,
+// the required capabilities the token does not hold. A token with "root" on a path
+// holds every capability; "deny" holds none.
+func missingCapabilities(ctx context.Context, client *vault.Client, paths []string, required []string) (map[string][]string, error) {
+	resp, err := client.System.QueryTokenSelfCapabilities(ctx, schema.QueryTokenSelfCapabilitiesRequest{
+		Paths: paths,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string][]string)
+	for _, p := range paths {
+		raw, _ := resp.Data[p].([]interface{})
+		var granted []string
+		for _, c := range raw {
+			if s, ok := c.(string); ok {
+				granted = append(granted, s)
+			}
+		}
+
+		if slices.Contains(granted, "root") {
+			continue
+		}
+		for _, req := range required {
+			if !slices.Contains(granted, req) {
+				missing[p] = append(missing[p], req)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// checkWriteCapabilities verifies the client's token holds create and update on every
+// path. Missing capabilities are logged as warnings, or returned as an error when strict
+// is set, so users get one clear message instead of a failure per secret.
+func checkWriteCapabilities(ctx context.Context, client *vault.Client, paths []string, strict bool) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	missing, err := missingCapabilities(ctx, client, paths, []string{"create", "update"})
+	if err != nil {
+		if strict {
+			return fmt.Errorf("unable to query token capabilities: %w", err)
+		}
+		slog.Warn("unable to query token capabilities, continuing without check", "error", err)
+		return nil
+	}
+
+	for _, p := range paths {
+		caps := missing[p]
+		if len(caps) == 0 {
+			continue
+		}
+		if strict {
+			return fmt.Errorf("insufficient permissions: token lacks %s capability on %q", strings.Join(caps, "/"), p)
+		}
+		slog.Warn("insufficient permissions, writes will likely fail", "path", p, "missing", caps)
+	}
+	return nil
+}
+
+// kvCapabilityPath returns the API path whose ACL governs the given KV operation ("read",
+// "write" or "list") under mount and prefix. KV v2 splits data and metadata under
+// separate path segments; KV v1 uses the mount path directly.
+func kvCapabilityPath(mount, version, operation, prefix string) string {
+	mount = NormalizeMount(mount)
+	prefix = strings.Trim(prefix, "/")
+
+	if version != "2" {
+		return path.Join(mount, prefix) + "/"
+	}
+	if operation == "list" {
+		return path.Join(mount, "metadata", prefix) + "/"
+	}
+	return path.Join(mount, "data", prefix) + "/"
+}
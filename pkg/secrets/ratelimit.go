@@ -0,0 +1,16 @@
+package secrets
+
+import (
+	"golang.org/x/time/rate"
+)
+
+// newRateLimiter returns a token-bucket limiter allowing rps Vault requests per second.
+//
+// A non-positive rps disables throttling. The same limiter should be shared by every
+// worker issuing requests so the limit applies to the operation as a whole.
+func newRateLimiter(rps float64) *rate.Limiter {
+	if rps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(rps), 1)
+}
@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault-client-go"
+	"golang.org/x/time/rate"
+)
+
+// RefPrefix marks a string value as a reference to a field of another secret, written
+// "ref:<mount>/<path>#<field>" (e.g. "ref:secret/shared/db#password").
+const RefPrefix = "ref:"
+
+// DereferenceSecret returns a copy of data, read from secretPath, in which every
+// top-level string value holding a reference is replaced by the value it points to.
+// The pointed-to secrets are read from client and routed with mounts, as returned by
+// GetSecretEngines.
+//
+// A reference may point at another reference, which is followed in turn; a chain that
+// leads back to a field already visited is reported as a cycle. A reference to a
+// missing secret or field is an error. data itself is never modified.
+func DereferenceSecret(ctx context.Context, client *vault.Client, mounts map[string]MountInfo, secretPath string, data map[string]interface{}) (map[string]interface{}, error) {
+	return dereference(ctx, client, nil, mounts, secretPath, data)
+}
+
+// dereference implements DereferenceSecret, waiting on limiter (if any) before reads.
+func dereference(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mounts map[string]MountInfo, secretPath string, data map[string]interface{}) (map[string]interface{}, error) {
+	var resolved map[string]interface{}
+	for key, value := range data {
+		ref, ok := value.(string)
+		if !ok || !strings.HasPrefix(ref, RefPrefix) {
+			continue
+		}
+		if resolved == nil {
+			resolved = make(map[string]interface{}, len(data))
+			for k, v := range data {
+				resolved[k] = v
+			}
+		}
+
+		origin := strings.Trim(secretPath, "/") + "#" + key
+		target, err := resolveRef(ctx, client, limiter, mounts, ref, []string{origin})
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", key, err)
+		}
+		resolved[key] = target
+	}
+
+	if resolved == nil {
+		return data, nil
+	}
+	return resolved, nil
+}
+
+// resolveRef returns the value ref points to, following chained references. chain holds
+// the fields visited so far, in order, to detect and report cycles.
+func resolveRef(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mounts map[string]MountInfo, ref string, chain []string) (interface{}, error) {
+	target := strings.TrimSpace(strings.TrimPrefix(ref, RefPrefix))
+	i := strings.LastIndex(target, "#")
+	if i <= 0 || i == len(target)-1 {
+		return nil, fmt.Errorf("invalid reference %q: want %s<mount>/<path>#<field>", ref, RefPrefix)
+	}
+	secretPath, field := strings.Trim(target[:i], "/"), target[i+1:]
+	target = secretPath + "#" + field
+
+	for _, visited := range chain {
+		if visited == target {
+			return nil, fmt.Errorf("reference cycle: %s -> %s", strings.Join(chain, " -> "), target)
+		}
+	}
+
+	mountInfo, relativePath, err := FindMountForSecret(secretPath, mounts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	data, err := ReadSecret(ctx, client, limiter, mountInfo, relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referenced secret %q: %w", secretPath, err)
+	}
+	value, ok := data[field]
+	if !ok {
+		return nil, fmt.Errorf("referenced secret %q has no field %q", secretPath, field)
+	}
+
+	if next, ok := value.(string); ok && strings.HasPrefix(next, RefPrefix) {
+		return resolveRef(ctx, client, limiter, mounts, next, append(chain, target))
+	}
+	return value, nil
+}
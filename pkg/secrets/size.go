@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// DefaultMaxSecretSize is the serialized size, in bytes, above which the CLI flags a
+// secret as too large. It matches the smallest common storage limit: Consul rejects
+// entries over 512 KiB, while integrated storage allows 1 MiB per entry by default.
+const DefaultMaxSecretSize = 512 * 1024
+
+// secretSize returns the size of data serialized as JSON, roughly what Vault stores.
+func secretSize(data map[string]interface{}) (int, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(raw), nil
+}
+
+// checkSecretSize reports a secret whose serialized data exceeds maxSize bytes, which
+// storage backends reject with obscure errors. It returns an error when strict is set
+// and only logs a warning otherwise. A maxSize of 0 disables the check.
+func checkSecretSize(ctx context.Context, secretPath string, data map[string]interface{}, maxSize int, strict bool) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	size, err := secretSize(data)
+	if err != nil {
+		return fmt.Errorf("failed to measure secret %q: %w", secretPath, err)
+	}
+	if size <= maxSize {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("secret %q is %d bytes, over the %d byte limit", secretPath, size, maxSize)
+	}
+	slog.WarnContext(ctx, "secret is larger than the size limit, the write will likely fail", "path", secretPath, "size", size, "limit", maxSize)
+	return nil
+}
+
+// checkSecretSizes runs checkSecretSize on every secret in paths before any is written,
+// so an oversized secret doesn't fail a batch partway through. With strict set, every
+// oversized secret is reported in one error.
+func checkSecretSizes(ctx context.Context, paths []string, secrets map[string]map[string]interface{}, maxSize int, strict bool) error {
+	var errs []error
+	for _, secretPath := range paths {
+		if err := checkSecretSize(ctx, secretPath, secrets[secretPath], maxSize, strict); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// TouchSecret rewrites the latest version of the KV v2 secret at relativePath with its
+// data unchanged, creating a new version, e.g. to reset rotation timers that key off a
+// version's creation time. The write is a check-and-set against the version read, so a
+// change made in between fails the touch instead of being reverted. It returns the new
+// version number.
+//
+// KV v1 keeps no versions, so touching a KV v1 secret is an error. limiter may be nil.
+func TouchSecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string) (int64, error) {
+	if mountInfo.Version != "2" {
+		return 0, fmt.Errorf("%w: touching needs KV v2 versions, %q is KV v%s", ErrUnsupportedKVVersion, mountInfo.MountPath, mountInfo.Version)
+	}
+	mount := NormalizeMount(mountInfo.MountPath)
+
+	var resp *vault.Response[schema.KvV2ReadResponse]
+	err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		resp, err = client.Secrets.KvV2Read(ctx, relativePath, vault.WithMountPath(mount), opt)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	logging.RegisterSecretValues(resp.Data.Data)
+
+	current, err := strconv.ParseInt(fmt.Sprint(resp.Data.Metadata["version"]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected version in secret metadata: %w", err)
+	}
+
+	return WriteSecretWithOptions(ctx, client, limiter, mountInfo, relativePath, resp.Data.Data, map[string]interface{}{"cas": current})
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+)
+
+// kvUpgradingMessage is part of the error Vault returns for every request to a KV mount
+// while it is being upgraded from v1 to v2. The mount already reports version 2, but
+// its data is still being rewritten in the versioned layout.
+const kvUpgradingMessage = "Upgrading from non-versioned to versioned data"
+
+const (
+	// kvUpgradeAttempts is how many times an upgrading mount is checked before giving up.
+	kvUpgradeAttempts = 6
+	// kvUpgradePollInterval is the wait between checks of an upgrading mount.
+	kvUpgradePollInterval = 5 * time.Second
+)
+
+// isKVUpgrading reports whether err is Vault rejecting a request because the KV mount is
+// upgrading from v1 to v2.
+func isKVUpgrading(err error) bool {
+	var respErr *vault.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	for _, message := range respErr.Errors {
+		if strings.Contains(message, kvUpgradingMessage) {
+			return true
+		}
+	}
+	return strings.Contains(string(respErr.RawResponseBytes), kvUpgradingMessage)
+}
+
+// waitForKVUpgrade makes sure a KV v2 mount is done upgrading from v1 before it is used,
+// so secrets are never read or written in the wrong format. An upgrade normally takes
+// seconds, so an upgrading mount is polled for a short while; if it is still upgrading
+// after that, an error wrapping ErrMountUpgrading asks the operator to wait. KV v1 mounts
+// and mounts whose config can't be read for other reasons are left to fail, if at all,
+// on the requests that follow.
+func waitForKVUpgrade(ctx context.Context, client *vault.Client, mountInfo MountInfo) error {
+	if mountInfo.Version != "2" {
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		_, err := client.Secrets.KvV2ReadConfiguration(ctx, vault.WithMountPath(mountInfo.MountPath))
+		if !isKVUpgrading(err) {
+			return nil
+		}
+		if attempt == kvUpgradeAttempts {
+			return fmt.Errorf("%w: %q is still upgrading from KV v1 to v2; wait for the upgrade to finish and try again", ErrMountUpgrading, mountInfo.MountPath)
+		}
+
+		slog.Warn("KV mount is upgrading from v1 to v2, waiting for it to finish", "mount", mountInfo.MountPath, "attempt", attempt)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(kvUpgradePollInterval):
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"unicode/utf8"
+)
+
+// warnLossyNumbers logs a warning listing the keys of data whose numeric values may not
+// be written exactly as they were given.
+//
+// Only create calls it. The Vault client decodes responses with json.Number, so copied
+// data never holds a float64, and create decodes JSON input the same way, so those
+// numbers are written byte-for-byte. A float64 comes from input decoded some other way,
+// such as a YAML float: it loses trailing zeros ("1.10" is written as 1.1), and above
+// 2^53 it can no longer represent every integer exactly. Only key names are logged,
+// never values.
+func warnLossyNumbers(ctx context.Context, secretPath string, data map[string]interface{}) {
+	if keys := lossyNumberKeys("", data); len(keys) > 0 {
+		sort.Strings(keys)
+		slog.WarnContext(ctx, "numeric values may not be written exactly as given", "path", secretPath, "keys", keys)
+	}
+}
+
+// lossyNumberKeys returns the dotted key paths of float64 values in v, descending into
+// nested maps and slices.
+func lossyNumberKeys(prefix string, v interface{}) []string {
+	var keys []string
+	switch val := v.(type) {
+	case float32, float64:
+		keys = append(keys, prefix)
+	case map[string]interface{}:
+		for k, child := range val {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			keys = append(keys, lossyNumberKeys(key, child)...)
+		}
+	case []interface{}:
+		for i, child := range val {
+			keys = append(keys, lossyNumberKeys(fmt.Sprintf("%s[%d]", prefix, i), child)...)
+		}
+	}
+	return keys
+}
+
+// unsupportedValueKeys returns the dotted key paths of values in v that have no JSON
+// representation Vault can store, such as YAML maps with non-string keys, descending
+// into nested maps and slices.
+func unsupportedValueKeys(prefix string, v interface{}) []string {
+	var keys []string
+	switch val := v.(type) {
+	case nil, string, bool, json.Number, float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	case map[string]interface{}:
+		for k, child := range val {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			keys = append(keys, unsupportedValueKeys(key, child)...)
+		}
+	case []interface{}:
+		for i, child := range val {
+			keys = append(keys, unsupportedValueKeys(fmt.Sprintf("%s[%d]", prefix, i), child)...)
+		}
+	default:
+		keys = append(keys, prefix)
+	}
+	return keys
+}
+
+// keyFilter selects which top-level fields of a secret are copied.
+type keyFilter struct {
+	include map[string]bool // nil keeps every field not excluded
+	exclude map[string]bool
+}
+
+// newKeyFilter returns a filter keeping only the fields named in include, if any, minus
+// those named in exclude.
+func newKeyFilter(include, exclude []string) keyFilter {
+	var f keyFilter
+	if len(include) > 0 {
+		f.include = make(map[string]bool, len(include))
+		for _, key := range include {
+			f.include[key] = true
+		}
+	}
+	if len(exclude) > 0 {
+		f.exclude = make(map[string]bool, len(exclude))
+		for _, key := range exclude {
+			f.exclude[key] = true
+		}
+	}
+	return f
+}
+
+// apply returns the fields of data that pass the filter. data itself is returned when
+// the filter is empty, and is never modified.
+func (f keyFilter) apply(data map[string]interface{}) map[string]interface{} {
+	if f.include == nil && f.exclude == nil {
+		return data
+	}
+	filtered := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if (f.include == nil || f.include[key]) && !f.exclude[key] {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// EncodeBase64Fields replaces the string value of each of keys present in data with its
+// standard base64 encoding, so binary material such as keys and certificates can be
+// stored in KV, which only holds strings. Keys absent from data are ignored.
+func EncodeBase64Fields(data map[string]interface{}, keys []string) error {
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %q is not a string and cannot be base64-encoded", key)
+		}
+		data[key] = base64.StdEncoding.EncodeToString([]byte(str))
+	}
+	return nil
+}
+
+// DecodeBase64TextFields reverses EncodeBase64Fields for fields holding base64-encoded
+// text, such as PEM certificates and keys, replacing the value of each of keys present
+// in data with its decoded content. Decoded values are JSON strings, which can only
+// hold text, so a field that decodes to binary data (non-UTF-8 bytes, e.g. a DER
+// certificate or a PKCS#12 keystore) is rejected rather than silently mangled; such
+// fields have to stay base64-encoded. Keys absent from data are ignored.
+func DecodeBase64TextFields(data map[string]interface{}, keys []string) error {
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %q is not a string and cannot be base64-decoded", key)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return fmt.Errorf("field %q is not valid base64: %w", key, err)
+		}
+		if !utf8.Valid(decoded) {
+			return fmt.Errorf("field %q decodes to binary data, which cannot be represented as a JSON string; leave it base64-encoded", key)
+		}
+		data[key] = string(decoded)
+	}
+	return nil
+}
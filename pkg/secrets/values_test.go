@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLossyNumberKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []string
+	}{
+		{
+			name: "json numbers are exact",
+			data: map[string]interface{}{"port": json.Number("5432"), "ratio": json.Number("1.10")},
+		},
+		{
+			name: "integers and strings are exact",
+			data: map[string]interface{}{"port": 5432, "user": "app", "on": true, "none": nil},
+		},
+		{
+			name: "top-level float",
+			data: map[string]interface{}{"ratio": 1.1, "port": 5432},
+			want: []string{"ratio"},
+		},
+		{
+			name: "nested floats",
+			data: map[string]interface{}{
+				"db":     map[string]interface{}{"timeout": 2.5, "host": "db"},
+				"limits": []interface{}{json.Number("1"), float32(0.5), map[string]interface{}{"max": 9.0}},
+			},
+			want: []string{"db.timeout", "limits[1]", "limits[2].max"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lossyNumberKeys("", tt.data)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("lossyNumberKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarnLossyNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	warnLossyNumbers(context.Background(), "secret/app", map[string]interface{}{"port": json.Number("5432")})
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected warning for exact numbers: %s", buf.String())
+	}
+
+	warnLossyNumbers(context.Background(), "secret/app", map[string]interface{}{
+		"ratio": 1.1,
+		"db":    map[string]interface{}{"timeout": 2.5},
+	})
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "path=secret/app", "keys=\"[db.timeout ratio]\""} {
+		if !strings.Contains(out, want) {
+			t.Errorf("warning %q does not contain %q", out, want)
+		}
+	}
+	for _, value := range []string{"1.1", "2.5"} {
+		if strings.Contains(out, value) {
+			t.Errorf("warning %q logs the value %s", out, value)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// VersionState describes the current version of a KV v2 secret.
+type VersionState struct {
+	Version   int64 `json:"version"`
+	Deleted   bool  `json:"deleted"`   // soft-deleted, and can be undeleted
+	Destroyed bool  `json:"destroyed"` // permanently destroyed
+}
+
+// ReadVersionState reads the current version number of the KV v2 secret at relativePath
+// under mount, and whether that version is deleted or destroyed, from the secret's
+// metadata without reading its data. limiter may be nil.
+func ReadVersionState(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mount, relativePath string) (VersionState, error) {
+	var metadata *vault.Response[schema.KvV2ReadMetadataResponse]
+	err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		metadata, err = client.Secrets.KvV2ReadMetadata(ctx, relativePath, vault.WithMountPath(NormalizeMount(mount)), opt)
+		return err
+	})
+	if err != nil {
+		return VersionState{}, err
+	}
+
+	current := metadata.Data.CurrentVersion
+	destroyed, deleted := versionLifecycle(metadata.Data.Versions[strconv.FormatInt(current, 10)])
+	return VersionState{Version: current, Deleted: deleted, Destroyed: destroyed}, nil
+}
+
+// ReadSecretVersion reads the data of one version of the KV v2 secret at relativePath
+// under mount. Vault answers 404 Not Found when the secret has no such version, or the
+// version is deleted or destroyed. limiter may be nil.
+func ReadSecretVersion(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mount, relativePath string, version int) (map[string]interface{}, error) {
+	var resp *vault.Response[schema.KvV2ReadResponse]
+	err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		resp, err = client.Secrets.KvV2Read(ctx, relativePath, vault.WithMountPath(NormalizeMount(mount)), versionParameter(version), opt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.RegisterSecretValues(resp.Data.Data)
+	return resp.Data.Data, nil
+}
+
+// versionParameter selects one version of a KV v2 secret on a read.
+func versionParameter(version int) vault.RequestOption {
+	return vault.WithQueryParameters(url.Values{"version": {strconv.Itoa(version)}})
+}
+
+// versionLifecycle reports whether a version, as described in a secret's metadata, has
+// been destroyed or soft-deleted.
+func versionLifecycle(raw interface{}) (destroyed, deleted bool) {
+	info, _ := raw.(map[string]interface{})
+	destroyed, _ = info["destroyed"].(bool)
+	deletionTime, _ := info["deletion_time"].(string)
+	// a deletion_time in the future is a pending delete_version_after, not a deletion
+	deletedAt, err := time.Parse(time.RFC3339Nano, deletionTime)
+	deleted = err == nil && deletedAt.Before(time.Now())
+	return destroyed, deleted
+}
+
+// copyAllVersions replays every version of a KV v2 secret from the source onto the
+// target, oldest first, and then reproduces its lifecycle state: versions destroyed on
+// the source are destroyed on the target, and soft-deleted versions are soft-deleted.
+//
+// Destroyed and deleted versions have no readable data, so an empty placeholder is
+// written in their place to keep the version history aligned before it is destroyed or
+// deleted. Target version numbers are taken from the write responses, so the history
+// stays faithful even if the target secret already had versions. Each version's data is
+// passed through keys before it is written.
+func copyAllVersions(ctx context.Context, sourceClient, targetClient *vault.Client, limiter *rate.Limiter, sourceMount, targetMount, sourcePath, targetPath string, keys keyFilter) error {
+	var metadata *vault.Response[schema.KvV2ReadMetadataResponse]
+	err := vaultclient.WithRetry(ctx, sourceClient, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+		metadata, err = sourceClient.Secrets.KvV2ReadMetadata(ctx, sourcePath, vault.WithMountPath(sourceMount), opt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var versions []int
+	for key := range metadata.Data.Versions {
+		if v, err := strconv.Atoi(key); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+
+	var destroyed, deleted []int32
+	for _, v := range versions {
+		isDestroyed, isDeleted := versionLifecycle(metadata.Data.Versions[strconv.Itoa(v)])
+
+		data := map[string]interface{}{}
+		if !isDestroyed && !isDeleted {
+			var secret *vault.Response[schema.KvV2ReadResponse]
+			err := vaultclient.WithRetry(ctx, sourceClient, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+				secret, err = sourceClient.Secrets.KvV2Read(ctx, sourcePath, vault.WithMountPath(sourceMount), versionParameter(v), opt)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read version %d: %w", v, err)
+			}
+			data = keys.apply(secret.Data.Data)
+		}
+
+		var written *vault.Response[schema.KvV2WriteResponse]
+		err = vaultclient.WithRetry(ctx, targetClient, limiter, func(ctx context.Context, opt vault.RequestOption) (err error) {
+			written, err = targetClient.Secrets.KvV2Write(ctx, targetPath, schema.KvV2WriteRequest{Data: data}, vault.WithMountPath(targetMount), opt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write version %d: %w", v, err)
+		}
+
+		switch {
+		case isDestroyed:
+			destroyed = append(destroyed, int32(written.Data.Version))
+		case isDeleted:
+			deleted = append(deleted, int32(written.Data.Version))
+		}
+		slog.DebugContext(ctx, "copied KV v2 version", "path", targetPath, "source_version", v, "target_version", written.Data.Version)
+	}
+
+	if len(destroyed) > 0 {
+		err := vaultclient.WithRetry(ctx, targetClient, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			_, err := targetClient.Secrets.KvV2DestroyVersions(ctx, targetPath, schema.KvV2DestroyVersionsRequest{Versions: destroyed}, vault.WithMountPath(targetMount), opt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to destroy versions %v: %w", destroyed, err)
+		}
+	}
+
+	if len(deleted) > 0 {
+		err := vaultclient.WithRetry(ctx, targetClient, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			_, err := targetClient.Secrets.KvV2DeleteVersions(ctx, targetPath, schema.KvV2DeleteVersionsRequest{Versions: deleted}, vault.WithMountPath(targetMount), opt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete versions %v: %w", deleted, err)
+		}
+	}
+
+	return nil
+}
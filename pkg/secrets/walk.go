@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/razahuss02/vaultx/internal/kvwalk"
+	"golang.org/x/time/rate"
+)
+
+// WalkOptions selects the part of a KV mount that WalkSecrets and ListSecrets traverse.
+type WalkOptions struct {
+	// Mount is the KV mount to traverse.
+	Mount string
+	// Prefix limits traversal to secrets under this path within the mount.
+	Prefix string
+	// MaxDepth is the maximum number of path levels to descend below Prefix; 1 visits
+	// only the secrets directly under it and 0 is unlimited.
+	MaxDepth int
+	// MaxSecrets aborts the traversal with ErrSecretLimit once more than this many
+	// secrets are found, as a guard against walking a far larger mount than intended.
+	// The first MaxSecrets secrets are still visited; 0 is unlimited.
+	MaxSecrets int
+	// KVVersion forces "1" or "2" behavior instead of detecting the mount's version.
+	KVVersion string
+	// Limiter, if not nil, is waited on before every LIST request of the traversal.
+	Limiter *rate.Limiter
+}
+
+// ListSecrets returns the full path of every secret selected by opts.
+func ListSecrets(ctx context.Context, client *vault.Client, opts WalkOptions) ([]string, error) {
+	var secretsList []string
+
+	err := WalkSecrets(ctx, client, opts, func(secretPath string) error {
+		secretsList = append(secretsList, secretPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secretsList, nil
+}
+
+// WalkSecrets traverses the mount selected by opts and calls fn with the full path of
+// each secret as soon as it is discovered, so callers can stream results without
+// buffering the mount. Traversal stops at the first error returned by fn.
+func WalkSecrets(ctx context.Context, client *vault.Client, opts WalkOptions, fn func(secretPath string) error) error {
+	kvVersion := opts.KVVersion
+	if kvVersion == "" {
+		mountInfo, err := LookupKVMount(ctx, client, opts.Mount)
+		if err != nil {
+			slog.Error("Failed to get source mount version", "error", err)
+			return err
+		}
+		kvVersion = mountInfo.Version
+	}
+
+	return kvwalk.WalkSecrets(ctx, client, NormalizeMount(opts.Mount), kvVersion, kvwalk.Options{Prefix: opts.Prefix, MaxDepth: opts.MaxDepth, MaxSecrets: opts.MaxSecrets, Limiter: opts.Limiter}, fn)
+}
@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault-client-go"
+)
+
+// listVault serves LIST requests for the KV mount "secret" of the given version from
+// tree, which maps each directory within the mount ("" for its root) to the keys under
+// it. Directories missing from tree are answered with 404.
+func listVault(t *testing.T, version string, tree map[string][]string) *vault.Client {
+	t.Helper()
+
+	prefix := "/v1/secret/"
+	if version == "2" {
+		prefix += "metadata/"
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list") != "true" || !strings.HasPrefix(r.URL.Path, prefix) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		keys, ok := tree[strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := vault.New(vault.WithAddress(srv.URL), vault.WithRetryConfiguration(vault.RetryConfiguration{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetToken("test-token"); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+var walkTree = map[string][]string{
+	"":           {"app/", "top"},
+	"app":        {"db", "nested/"},
+	"app/nested": {"deep"},
+}
+
+func TestListSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WalkOptions
+		want []string
+	}{
+		{
+			name: "whole mount depth first",
+			want: []string{"secret/app/db", "secret/app/nested/deep", "secret/top"},
+		},
+		{
+			name: "prefix",
+			opts: WalkOptions{Prefix: "/app/"},
+			want: []string{"secret/app/db", "secret/app/nested/deep"},
+		},
+		{
+			name: "max depth",
+			opts: WalkOptions{MaxDepth: 2},
+			want: []string{"secret/app/db", "secret/top"},
+		},
+	}
+	for _, version := range []string{"1", "2"} {
+		client := listVault(t, version, walkTree)
+		for _, tt := range tests {
+			t.Run("v"+version+" "+tt.name, func(t *testing.T) {
+				tt.opts.Mount, tt.opts.KVVersion = "secret", version
+				got, err := ListSecrets(context.Background(), client, tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("listed %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListSecretsLargeListing(t *testing.T) {
+	// Vault returns every key of a directory in one LIST response; make sure none of
+	// a large one is dropped
+	const n = 20000
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("secret-%05d", i)
+	}
+	client := listVault(t, "2", map[string][]string{"": keys})
+
+	got, err := ListSecrets(context.Background(), client, WalkOptions{Mount: "secret", KVVersion: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != n || got[0] != "secret/secret-00000" || got[n-1] != fmt.Sprintf("secret/secret-%05d", n-1) {
+		t.Fatalf("listed %d secrets, want %d in listing order", len(got), n)
+	}
+}
+
+func TestListSecretsSkipsMissingDirectory(t *testing.T) {
+	// "gone/" is listed but deleted before it is traversed, so listing it returns 404
+	client := listVault(t, "2", map[string][]string{
+		"":     {"gone/", "kept/", "top"},
+		"kept": {"db"},
+	})
+
+	got, err := ListSecrets(context.Background(), client, WalkOptions{Mount: "secret", KVVersion: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"secret/kept/db", "secret/top"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("listed %v, want %v", got, want)
+	}
+}
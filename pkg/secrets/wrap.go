@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+	"github.com/razahuss02/vaultx/internal/logging"
+	"github.com/razahuss02/vaultx/internal/vaultclient"
+	"golang.org/x/time/rate"
+)
+
+// ReadSecretWrapped reads the secret at relativePath under the given mount like
+// ReadSecret, but asks Vault to response-wrap it: instead of the secret, Vault returns a
+// single-use wrapping token valid for ttl, to be redeemed with UnwrapSecret. limiter may
+// be nil.
+func ReadSecretWrapped(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string, ttl time.Duration) (string, error) {
+	return readSecretWrapped(ctx, client, limiter, mountInfo, relativePath, ttl)
+}
+
+// readSecretWrapped is ReadSecretWrapped with extra options for the read request, such
+// as a KV v2 version to read.
+func readSecretWrapped(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, relativePath string, ttl time.Duration, extra ...vault.RequestOption) (string, error) {
+	mount := NormalizeMount(mountInfo.MountPath)
+	wrap := vault.WithResponseWrapping(ttl)
+
+	var wrapInfo *vault.ResponseWrapInfo
+	switch mountInfo.Version {
+	case "2":
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			resp, err := client.Secrets.KvV2Read(ctx, relativePath, append([]vault.RequestOption{vault.WithMountPath(mount), wrap, opt}, extra...)...)
+			if err == nil {
+				wrapInfo = resp.WrapInfo
+			}
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+	case "1":
+		err := vaultclient.WithRetry(ctx, client, limiter, func(ctx context.Context, opt vault.RequestOption) error {
+			resp, err := client.Secrets.KvV1Read(ctx, relativePath, append([]vault.RequestOption{vault.WithMountPath(mount), wrap, opt}, extra...)...)
+			if err == nil {
+				wrapInfo = resp.WrapInfo
+			}
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, mountInfo.Version)
+	}
+
+	if wrapInfo == nil || wrapInfo.Token == "" {
+		return "", errors.New("vault returned no wrapping token for the wrapped read")
+	}
+	return wrapInfo.Token, nil
+}
+
+// UnwrapSecret redeems a wrapping token returned by ReadSecretWrapped for the same mount
+// and returns the secret's data. A wrapping token can be used only once, so the unwrap
+// is not retried; an error usually means the token expired or was already used,
+// possibly by someone else, and the secret should be read again. limiter may be nil.
+func UnwrapSecret(ctx context.Context, client *vault.Client, limiter *rate.Limiter, mountInfo MountInfo, token string) (map[string]interface{}, error) {
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	switch mountInfo.Version {
+	case "2":
+		resp, err := vault.Unwrap[schema.KvV2ReadResponse](ctx, client, token)
+		if err != nil {
+			return nil, err
+		}
+		logging.RegisterSecretValues(resp.Data.Data)
+		return resp.Data.Data, nil
+
+	case "1":
+		resp, err := vault.Unwrap[map[string]interface{}](ctx, client, token)
+		if err != nil {
+			return nil, err
+		}
+		logging.RegisterSecretValues(resp.Data)
+		return resp.Data, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKVVersion, mountInfo.Version)
+	}
+}